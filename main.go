@@ -13,37 +13,70 @@ import (
 var (
 	// path to input data set
 	input string
+	// index of the data set label column: -1 if data set is not labeled
+	labelCol int
+	// data set header flag
+	header bool
 	// feature scaling flag
 	scale bool
-	// map dimensions: 2D only [for now]
+	// map dimensions: 1D, 2D or 3D
 	dims string
-	// map grid type: planar
+	// map grid type: planar, toroid, cylinder
 	grid string
+	// map dimension cylinder grid wraps around
+	axis int
 	// map unit shape type: hexagon, rectangle
 	ushape string
 	// initial SOM unit neihbourhood radius
-	radius int
-	// radius decay strategy: lin, exp
+	radius float64
+	// final SOM unit neihbourhood radius
+	rfinal float64
+	// radius decay strategy: lin, exp, inv, power, step
 	rdecay string
 	// neighbourhood func: gaussian, bubble, mexican
 	neighb string
 	// initial SOM learning rate
-	lrate int
-	// learning rate decay strategy: lin, exp
+	lrate float64
+	// final SOM learning rate
+	lfinal float64
+	// learning rate decay strategy: lin, exp, inv, power, step
 	ldecay string
+	// distance metric used in BMU search
+	metric string
+	// distance metric used to compute distances between SOM units
+	gridMetric string
+	// number of cached grid distance rows
+	gridCache int
+	// training algorithm: seq, batch
+	training string
+	// number of training iterations
+	iters int
+	// number of batch training workers
+	workers int
 )
 
 func init() {
 	flag.StringVar(&input, "input", "", "Path to input data set")
+	flag.IntVar(&labelCol, "labelcol", -1, "Index of data set label column: -1 if data set is not labeled")
+	flag.BoolVar(&header, "header", false, "First record of data set is a header with feature names")
 	flag.BoolVar(&scale, "scale", false, "Request data scaling")
 	flag.StringVar(&dims, "dims", "", "comma-separated SOM dimensions")
 	flag.StringVar(&grid, "grid", "planar", "SOM grid")
+	flag.IntVar(&axis, "axis", 0, "SOM map dimension cylinder grid wraps around")
 	flag.StringVar(&ushape, "ushape", "hexagon", "SOM map unit shape")
-	flag.IntVar(&radius, "radius", 0, "SOM neihbourhood starting radius")
+	flag.Float64Var(&radius, "radius", 0, "SOM neihbourhood starting radius")
+	flag.Float64Var(&rfinal, "rfinal", 0, "SOM neihbourhood final radius")
 	flag.StringVar(&rdecay, "rdecay", "lin", "Radius decay strategy")
 	flag.StringVar(&neighb, "neighb", "gaussian", "SOM neighbourhood function")
-	flag.IntVar(&lrate, "lrate", 0, "SOM initial learning rate")
+	flag.Float64Var(&lrate, "lrate", 0, "SOM initial learning rate")
+	flag.Float64Var(&lfinal, "lfinal", 0, "SOM final learning rate")
 	flag.StringVar(&ldecay, "ldecay", "lin", "Learning rate decay strategy")
+	flag.StringVar(&metric, "metric", "euclidean", "BMU search distance metric: euclidean, sqeuclidean, manhattan, chebyshev, cosine, minkowski:<p>")
+	flag.StringVar(&gridMetric, "gridmetric", "euclidean", "SOM grid distance metric: any distance metric, lattice, lattice-chebyshev")
+	flag.IntVar(&gridCache, "gridcache", 0, "Number of cached grid distance rows")
+	flag.StringVar(&training, "training", "seq", "SOM training algorithm: seq, batch")
+	flag.IntVar(&iters, "iters", 1000, "Number of training iterations")
+	flag.IntVar(&workers, "workers", 1, "Number of batch training workers")
 }
 
 func parseCliFlags() error {
@@ -53,6 +86,10 @@ func parseCliFlags() error {
 	if input == "" {
 		return fmt.Errorf("Invalid path to input data: %s\n", input)
 	}
+	// training algorithm must be either seq or batch
+	if training != "seq" && training != "batch" {
+		return fmt.Errorf("Unsupported training algorithm: %s\n", training)
+	}
 	return nil
 }
 
@@ -69,7 +106,15 @@ func main() {
 		os.Exit(1)
 	}
 	// load data set from a file in provided path
-	ds, err := dataset.New(input)
+	var ds *dataset.DataSet
+	switch {
+	case labelCol >= 0:
+		ds, err = dataset.NewLabeled(input, labelCol, header)
+	case header:
+		ds, err = dataset.NewWithHeader(input)
+	default:
+		ds, err = dataset.New(input)
+	}
 	if err != nil {
 		fmt.Printf("Unable to load Data Set: %s\n", err)
 		os.Exit(1)
@@ -81,15 +126,22 @@ func main() {
 	}
 	// SOM configuration
 	config := &som.Config{
-		Dims:     mdims,
-		InitFunc: som.RandInit,
-		Grid:     grid,
-		UShape:   ushape,
-		Radius:   radius,
-		RDecay:   rdecay,
-		NeighbFn: neighb,
-		LRate:    lrate,
-		LDecay:   ldecay,
+		Dims:        mdims,
+		InitFunc:    som.RandInit,
+		Grid:        grid,
+		Axis:        axis,
+		UShape:      ushape,
+		Radius:      radius,
+		RadiusFinal: rfinal,
+		RDecay:      rdecay,
+		NeighbFn:    neighb,
+		LRate:       lrate,
+		LRateFinal:  lfinal,
+		LDecay:      ldecay,
+		Metric:      metric,
+		GridMetric:  gridMetric,
+		GridCache:   gridCache,
+		Workers:     workers,
 	}
 	// create new SOM map
 	smap, err := som.NewMap(config, data)
@@ -97,5 +149,47 @@ func main() {
 		fmt.Printf("Failed to create new SOM: %s\n", err)
 		os.Exit(1)
 	}
+	// train SOM
+	train := smap.TrainSeq
+	if training == "batch" {
+		train = smap.TrainBatch
+	}
+	if err := train(data, iters); err != nil {
+		fmt.Printf("Failed to train SOM: %s\n", err)
+		os.Exit(1)
+	}
+	// report SOM quality
+	qe, err := smap.QuantError(data)
+	if err != nil {
+		fmt.Printf("Failed to compute quantization error: %s\n", err)
+		os.Exit(1)
+	}
+	te, err := smap.TopoError(data)
+	if err != nil {
+		fmt.Printf("Failed to compute topographic error: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Quantization error: %f\nTopographic error: %f\n", qe, te)
+	// label SOM units by majority vote
+	if labels := ds.Labels(); labels != nil {
+		unitLabels, err := smap.MajorityLabels(data, labels)
+		if err != nil {
+			fmt.Printf("Failed to label SOM units: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Unit labels: %v\n", unitLabels)
+		// classify training data by BMU labels
+		predictor, err := som.NewPredictor(smap, data, labels, 1, false)
+		if err != nil {
+			fmt.Printf("Failed to create SOM predictor: %s\n", err)
+			os.Exit(1)
+		}
+		accuracy, err := som.Accuracy(predictor, data, labels)
+		if err != nil {
+			fmt.Printf("Failed to classify data: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Training accuracy: %f\n", accuracy)
+	}
 	fmt.Printf("Hello Go SOM: %v\n", smap)
 }
@@ -0,0 +1,153 @@
+// Package cluster implements clustering algorithms and cluster validity indices
+// which can be used to cluster SOM codebook vectors.
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// Func clusters rows of data matrix into k clusters.
+// It returns a slice which contains cluster labels of all rows: labels range from 0 to k-1.
+type Func func(data *mat64.Dense, k int) ([]int, error)
+
+// ValidityIndex is a cluster validity index used to compare clusterings
+type ValidityIndex struct {
+	// Fn computes the index of data rows clustered according to labels
+	Fn func(data *mat64.Dense, labels []int) (float64, error)
+	// Minimize is true if lower index values indicate better clustering
+	Minimize bool
+}
+
+// Validity maps supported cluster validity indices to their implementations
+var Validity = map[string]ValidityIndex{
+	"daviesbouldin": {Fn: DaviesBouldin, Minimize: true},
+	"silhouette":    {Fn: Silhouette, Minimize: false},
+}
+
+// BestK clusters rows of data matrix into every number of clusters between kMin and kMax
+// using cluster function and returns the number of clusters with the best value of the
+// requested validity index along with the cluster labels of data rows.
+// Clusterings which contain less than k clusters, e.g. because data contains less than k
+// distinct rows, are skipped.
+// It returns error if the clustering fails, if kMin is smaller than 2, if kMax is smaller
+// than kMin, if the validity index is not supported or if none of the clusterings contains
+// the requested number of clusters.
+func BestK(data *mat64.Dense, kMin, kMax int, cluster Func, index string) (int, []int, error) {
+	validity, ok := Validity[index]
+	if !ok {
+		return 0, nil, fmt.Errorf("Unsupported validity index: %s\n", index)
+	}
+	if kMin < 2 || kMax < kMin {
+		return 0, nil, fmt.Errorf("Invalid range of clusters: %d, %d\n", kMin, kMax)
+	}
+	bestK, bestVal := 0, 0.0
+	var bestLabels []int
+	for k := kMin; k <= kMax; k++ {
+		labels, err := cluster(data, k)
+		if err != nil {
+			return 0, nil, err
+		}
+		if clusters(labels) != k {
+			continue
+		}
+		val, err := validity.Fn(data, labels)
+		if err != nil {
+			return 0, nil, err
+		}
+		if validity.Minimize {
+			val = -val
+		}
+		if bestLabels == nil || val > bestVal {
+			bestK, bestVal, bestLabels = k, val, labels
+		}
+	}
+	if bestLabels == nil {
+		return 0, nil, fmt.Errorf("No clustering with requested number of clusters: %d, %d\n", kMin, kMax)
+	}
+	return bestK, bestLabels, nil
+}
+
+// clusters returns the number of distinct labels
+func clusters(labels []int) int {
+	distinct := make(map[int]bool)
+	for _, label := range labels {
+		distinct[label] = true
+	}
+	return len(distinct)
+}
+
+// validateData checks if the rows of data matrix can be clustered into k clusters.
+// It returns error if data is nil or if k is not positive or exceeds the number of rows.
+func validateData(data *mat64.Dense, k int) error {
+	if data == nil {
+		return fmt.Errorf("Invalid data matrix: %v\n", data)
+	}
+	if rows, _ := data.Dims(); k <= 0 || k > rows {
+		return fmt.Errorf("Invalid number of clusters: %d\n", k)
+	}
+	return nil
+}
+
+// validateLabels checks if labels can be used to compute validity index of data clustering.
+// It returns the largest label increased by one or error if data is nil, if the number of labels
+// does not match the number of data rows, if any of the labels is negative or if there are less
+// than 2 clusters.
+func validateLabels(data *mat64.Dense, labels []int) (int, error) {
+	if data == nil {
+		return 0, fmt.Errorf("Invalid data matrix: %v\n", data)
+	}
+	if rows, _ := data.Dims(); len(labels) != rows {
+		return 0, fmt.Errorf("Incorrect number of labels: %d\n", len(labels))
+	}
+	k := 0
+	clusters := make(map[int]bool)
+	for _, label := range labels {
+		if label < 0 {
+			return 0, fmt.Errorf("Invalid cluster label: %d\n", label)
+		}
+		if label >= k {
+			k = label + 1
+		}
+		clusters[label] = true
+	}
+	if len(clusters) < 2 {
+		return 0, fmt.Errorf("Insufficient number of clusters: %d\n", len(clusters))
+	}
+	return k, nil
+}
+
+// centroids returns a matrix which contains centroids of k clusters stored in rows along
+// with the sizes of the clusters. Centroids of empty clusters are zero vectors.
+func centroids(data *mat64.Dense, labels []int, k int) (*mat64.Dense, []int) {
+	_, cols := data.Dims()
+	centers := mat64.NewDense(k, cols, nil)
+	sizes := make([]int, k)
+	for i, label := range labels {
+		floats.Add(centers.RawRowView(label), data.RawRowView(i))
+		sizes[label]++
+	}
+	for label, size := range sizes {
+		if size > 0 {
+			floats.Scale(1/float64(size), centers.RawRowView(label))
+		}
+	}
+	return centers, sizes
+}
+
+// relabel renumbers labels in the order of their first occurrence
+func relabel(labels []int) []int {
+	ids := make(map[int]int)
+	relabeled := make([]int, len(labels))
+	for i, label := range labels {
+		id, ok := ids[label]
+		if !ok {
+			id = len(ids)
+			ids[label] = id
+		}
+		relabeled[i] = id
+	}
+	return relabeled
+}
@@ -0,0 +1,95 @@
+package cluster
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+// blobs contains 3 well separated clusters of 4 rows each
+var blobs = mat64.NewDense(12, 2, []float64{
+	0.0, 0.0,
+	10.0, 10.0,
+	0.1, 0.2,
+	10.2, 10.1,
+	-10.0, 5.0,
+	0.2, 0.1,
+	9.9, 10.0,
+	-10.1, 5.2,
+	-0.1, 0.0,
+	10.0, 9.8,
+	-9.8, 4.9,
+	-10.0, 5.1,
+})
+
+// blobLabels are the labels of blobs rows
+var blobLabels = []int{0, 1, 0, 1, 2, 0, 1, 2, 0, 1, 2, 2}
+
+func TestBestK(t *testing.T) {
+	assert := assert.New(t)
+
+	for index := range Validity {
+		for _, fn := range []Func{KMeans, wardFunc} {
+			k, labels, err := BestK(blobs, 2, 6, fn, index)
+			assert.NoError(err)
+			assert.Equal(3, k, index)
+			assert.Equal(blobLabels, labels, index)
+		}
+	}
+
+	// clusterings with less than k clusters are skipped
+	dups := mat64.NewDense(6, 1, []float64{0, 0, 0, 5, 5, 5})
+	for index := range Validity {
+		k, labels, err := BestK(dups, 2, 4, KMeans, index)
+		assert.NoError(err)
+		assert.Equal(2, k, index)
+		assert.Equal([]int{0, 0, 0, 1, 1, 1}, labels)
+	}
+	single := func(data *mat64.Dense, k int) ([]int, error) {
+		rows, _ := data.Dims()
+		return make([]int, rows), nil
+	}
+
+	errCases := []struct {
+		kMin  int
+		kMax  int
+		fn    Func
+		index string
+	}{
+		{2, 4, single, "silhouette"},
+		{2, 4, KMeans, "foobar"},
+		{1, 4, KMeans, "silhouette"},
+		{4, 3, KMeans, "silhouette"},
+		{2, 13, KMeans, "silhouette"},
+		{2, 4, func(*mat64.Dense, int) ([]int, error) { return nil, errors.New("Test error") }, "silhouette"},
+	}
+	for _, tc := range errCases {
+		k, labels, err := BestK(blobs, tc.kMin, tc.kMax, tc.fn, tc.index)
+		assert.Equal(0, k)
+		assert.Nil(labels)
+		assert.Error(err)
+	}
+}
+
+// wardFunc clusters data using Ward linkage
+func wardFunc(data *mat64.Dense, k int) ([]int, error) {
+	return Agglomerative(data, k, "ward", nil)
+}
+
+func TestRelabel(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal([]int{0, 0, 1, 2, 1}, relabel([]int{5, 5, 2, 7, 2}))
+	assert.Equal([]int{}, relabel([]int{}))
+}
+
+func TestCentroids(t *testing.T) {
+	assert := assert.New(t)
+
+	data := mat64.NewDense(4, 1, []float64{1, 3, 10, 20})
+	centers, sizes := centroids(data, []int{0, 0, 2, 2}, 3)
+	assert.True(mat64.Equal(mat64.NewDense(3, 1, []float64{2, 0, 15}), centers))
+	assert.Equal([]int{2, 0, 2}, sizes)
+}
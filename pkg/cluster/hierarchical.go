@@ -0,0 +1,143 @@
+package cluster
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// Linkage maps supported agglomerative clustering linkage criteria to Lance-Williams
+// formulas which compute the distance between cluster k and cluster merged from clusters
+// i and j from the distances dki, dkj, dij between the clusters and their sizes ni, nj, nk.
+var Linkage = map[string]func(dki, dkj, dij float64, ni, nj, nk int) float64{
+	"single":   singleLinkage,
+	"complete": completeLinkage,
+	"ward":     wardLinkage,
+}
+
+// Agglomerative clusters rows of data matrix into k clusters using agglomerative hierarchical
+// clustering with the requested linkage criterion: every row starts in its own cluster and the
+// two closest clusters are merged until there are k clusters left. If connectivity is not nil,
+// it must contain indices of the rows connected to each row and only connected clusters are
+// merged: you can use it to restrict clusters of SOM codebook vectors to neighbouring SOM units.
+// Distances between rows are measured using Euclidean distance, Ward linkage uses squared
+// Euclidean distance. Clustering needs O(N^2) memory and O(N^3) time for N data rows.
+// Clusters are labelled in the order of their first occurrence in data.
+// It returns error if data is nil, if k is not positive or exceeds the number of rows, if the
+// linkage is not supported, if connectivity is invalid or if the rows connected according to
+// connectivity can't be merged into k clusters.
+func Agglomerative(data *mat64.Dense, k int, linkage string, connectivity [][]int) ([]int, error) {
+	if err := validateData(data, k); err != nil {
+		return nil, err
+	}
+	linkFn, ok := Linkage[linkage]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported linkage: %s\n", linkage)
+	}
+	rows, _ := data.Dims()
+	conn, err := connected(rows, connectivity)
+	if err != nil {
+		return nil, err
+	}
+	// pairwise distances between clusters
+	dists := make([][]float64, rows)
+	for i := range dists {
+		dists[i] = make([]float64, rows)
+		for j := range dists[i] {
+			dists[i][j] = sqDist(data.RawRowView(i), data.RawRowView(j))
+			if linkage != "ward" {
+				dists[i][j] = math.Sqrt(dists[i][j])
+			}
+		}
+	}
+	// labels[i] is the cluster of row i: clusters are labelled by the index of one of their rows
+	labels := make([]int, rows)
+	sizes := make([]int, rows)
+	active := make([]bool, rows)
+	for i := range labels {
+		labels[i], sizes[i], active[i] = i, 1, true
+	}
+	for clusters := rows; clusters > k; clusters-- {
+		// find the closest pair of connected clusters
+		a, b := -1, -1
+		for i := 0; i < rows; i++ {
+			if !active[i] {
+				continue
+			}
+			for j := i + 1; j < rows; j++ {
+				if !active[j] || (conn != nil && !conn[i][j]) {
+					continue
+				}
+				if a < 0 || dists[i][j] < dists[a][b] {
+					a, b = i, j
+				}
+			}
+		}
+		if a < 0 {
+			return nil, fmt.Errorf("Could not merge connected data into %d clusters\n", k)
+		}
+		// merge cluster b into cluster a
+		for c := 0; c < rows; c++ {
+			if !active[c] || c == a || c == b {
+				continue
+			}
+			dists[a][c] = linkFn(dists[c][a], dists[c][b], dists[a][b], sizes[a], sizes[b], sizes[c])
+			dists[c][a] = dists[a][c]
+			if conn != nil {
+				conn[a][c] = conn[a][c] || conn[b][c]
+				conn[c][a] = conn[a][c]
+			}
+		}
+		sizes[a] += sizes[b]
+		active[b] = false
+		for i, label := range labels {
+			if label == b {
+				labels[i] = a
+			}
+		}
+	}
+	return relabel(labels), nil
+}
+
+// connected returns a symmetric matrix which marks connected rows according to connectivity.
+// It returns nil if connectivity is nil or error if connectivity contains invalid row indices
+// or if its length does not match the number of rows.
+func connected(rows int, connectivity [][]int) ([][]bool, error) {
+	if connectivity == nil {
+		return nil, nil
+	}
+	if len(connectivity) != rows {
+		return nil, fmt.Errorf("Incorrect connectivity size: %d\n", len(connectivity))
+	}
+	conn := make([][]bool, rows)
+	for i := range conn {
+		conn[i] = make([]bool, rows)
+	}
+	for i, neighbs := range connectivity {
+		for _, j := range neighbs {
+			if j < 0 || j >= rows {
+				return nil, fmt.Errorf("Invalid connected row index: %d\n", j)
+			}
+			conn[i][j], conn[j][i] = true, true
+		}
+	}
+	return conn, nil
+}
+
+// singleLinkage returns the distance between the closest rows of clusters
+func singleLinkage(dki, dkj, dij float64, ni, nj, nk int) float64 {
+	return math.Min(dki, dkj)
+}
+
+// completeLinkage returns the distance between the farthest rows of clusters
+func completeLinkage(dki, dkj, dij float64, ni, nj, nk int) float64 {
+	return math.Max(dki, dkj)
+}
+
+// wardLinkage returns the increase of the within-cluster sum of squares caused by merging
+// clusters: up to a constant factor, which does not change the order of merges.
+func wardLinkage(dki, dkj, dij float64, ni, nj, nk int) float64 {
+	n := float64(ni + nj + nk)
+	return (float64(ni+nk)*dki + float64(nj+nk)*dkj - float64(nk)*dij) / n
+}
@@ -0,0 +1,75 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgglomerative(t *testing.T) {
+	assert := assert.New(t)
+
+	for linkage := range Linkage {
+		labels, err := Agglomerative(blobs, 3, linkage, nil)
+		assert.NoError(err)
+		assert.Equal(blobLabels, labels, linkage)
+		labels, err = Agglomerative(blobs, 12, linkage, nil)
+		assert.NoError(err)
+		assert.Equal([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, labels)
+		labels, err = Agglomerative(blobs, 1, linkage, nil)
+		assert.NoError(err)
+		assert.Equal(make([]int, 12), labels)
+	}
+
+	// single linkage chains, complete linkage does not
+	data := mat64.NewDense(5, 1, []float64{0, 1, 2, 3, 4.5})
+	labels, err := Agglomerative(data, 2, "single", nil)
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 0, 0, 1}, labels)
+	labels, err = Agglomerative(data, 2, "complete", nil)
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 1, 1, 1}, labels)
+
+	// chain connectivity: rows 0 and 5 are close, but not connected
+	data = mat64.NewDense(6, 1, []float64{0, 5, 6, 7, 8, 0.1})
+	conn := [][]int{{1}, {2}, {3}, {4}, {5}, {}}
+	labels, err = Agglomerative(data, 2, "single", nil)
+	assert.NoError(err)
+	assert.Equal([]int{0, 1, 1, 1, 1, 0}, labels)
+	labels, err = Agglomerative(data, 2, "single", conn)
+	assert.NoError(err)
+	assert.Equal([]int{0, 1, 1, 1, 1, 1}, labels)
+	// disconnected rows can't be merged into a single cluster
+	labels, err = Agglomerative(data, 1, "single", [][]int{{1}, {2}, {}, {4}, {5}, {}})
+	assert.Nil(labels)
+	assert.Error(err)
+
+	errCases := []struct {
+		data    *mat64.Dense
+		k       int
+		linkage string
+		conn    [][]int
+	}{
+		{nil, 2, "ward", nil},
+		{blobs, 0, "ward", nil},
+		{blobs, 13, "ward", nil},
+		{blobs, 2, "foobar", nil},
+		{data, 2, "ward", [][]int{{1}}},
+		{data, 2, "ward", [][]int{{1}, {2}, {3}, {4}, {6}, {}}},
+	}
+	for _, tc := range errCases {
+		labels, err := Agglomerative(tc.data, tc.k, tc.linkage, tc.conn)
+		assert.Nil(labels)
+		assert.Error(err)
+	}
+}
+
+func TestWardLinkage(t *testing.T) {
+	assert := assert.New(t)
+
+	// merging cluster {0} with cluster {2, 4} merged from {2} and {4}:
+	// increase of within-cluster sum of squares is 2/3*(0-3)^2 = 6, which is half of the distance
+	dist := wardLinkage(4, 16, 4, 1, 1, 1)
+	assert.InDelta(12.0, dist, 1e-9)
+}
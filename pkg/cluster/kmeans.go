@@ -0,0 +1,136 @@
+package cluster
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+const (
+	// kmeansSeed seeds the random generator used to pick initial centroids
+	kmeansSeed = 55
+	// kmeansIters is the maximum number of k-means iterations
+	kmeansIters = 100
+)
+
+// KMeans clusters rows of data matrix into k clusters using k-means algorithm.
+// Initial centroids are picked using k-means++ seeding with a fixed random seed, so the
+// clustering is deterministic. Distances are measured using Euclidean distance.
+// Clusters which end up empty are reseeded with the rows farthest from their cluster centroids,
+// so exactly k clusters are returned unless data contains less than k distinct rows.
+// Clusters are labelled in the order of their first occurrence in data.
+// It returns error if data is nil or if k is not positive or exceeds the number of rows.
+func KMeans(data *mat64.Dense, k int) ([]int, error) {
+	if err := validateData(data, k); err != nil {
+		return nil, err
+	}
+	rows, _ := data.Dims()
+	centers := seedCentroids(data, k, rand.New(rand.NewSource(kmeansSeed)))
+	labels := make([]int, rows)
+	reseeded := false
+	for iter := 0; iter < kmeansIters; iter++ {
+		changed := iter == 0 || reseeded
+		for i := range labels {
+			if closest, _ := closestRow(data.RawRowView(i), centers); closest != labels[i] {
+				labels[i] = closest
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+		newCenters, sizes := centroids(data, labels, k)
+		for label, size := range sizes {
+			if size > 0 {
+				centers.SetRow(label, newCenters.RawRowView(label))
+			}
+		}
+		reseeded = reseed(data, labels, centers, sizes)
+	}
+	return relabel(labels), nil
+}
+
+// reseed moves centroids of empty clusters to the rows farthest from their cluster centroids
+// picked from clusters with more than one row and assigns the rows to the empty clusters.
+// Rows which coincide with their centroids are never picked, so clusters stay empty if there
+// are no other rows. It returns true if any of the empty clusters was reseeded.
+func reseed(data *mat64.Dense, labels []int, centers *mat64.Dense, sizes []int) bool {
+	reseeded := false
+	for label, size := range sizes {
+		if size > 0 {
+			continue
+		}
+		far, farDist := -1, 0.0
+		for i, l := range labels {
+			if sizes[l] < 2 {
+				continue
+			}
+			if dist := sqDist(data.RawRowView(i), centers.RawRowView(l)); dist > farDist {
+				far, farDist = i, dist
+			}
+		}
+		if far < 0 {
+			break
+		}
+		sizes[labels[far]]--
+		labels[far], sizes[label] = label, 1
+		centers.SetRow(label, data.RawRowView(far))
+		reseeded = true
+	}
+	return reseeded
+}
+
+// seedCentroids picks k rows of data matrix as initial centroids using k-means++ seeding:
+// every next centroid is picked with probability proportional to its squared distance
+// from the closest centroid picked so far.
+func seedCentroids(data *mat64.Dense, k int, rnd *rand.Rand) *mat64.Dense {
+	rows, cols := data.Dims()
+	centers := mat64.NewDense(k, cols, nil)
+	centers.SetRow(0, data.RawRowView(rnd.Intn(rows)))
+	dists := make([]float64, rows)
+	for c := 1; c < k; c++ {
+		picked := centers.View(0, 0, c, cols).(*mat64.Dense)
+		for i := range dists {
+			_, dists[i] = closestRow(data.RawRowView(i), picked)
+		}
+		sum := floats.Sum(dists)
+		// all remaining rows coincide with picked centroids
+		if sum == 0 {
+			centers.SetRow(c, data.RawRowView(rnd.Intn(rows)))
+			continue
+		}
+		target, next := rnd.Float64()*sum, rows-1
+		for i, dist := range dists {
+			if target -= dist; target < 0 {
+				next = i
+				break
+			}
+		}
+		centers.SetRow(c, data.RawRowView(next))
+	}
+	return centers
+}
+
+// closestRow returns index of the row of matrix m closest to x along with its squared
+// Euclidean distance from x
+func closestRow(x []float64, m *mat64.Dense) (int, float64) {
+	rows, _ := m.Dims()
+	closest, minDist := 0, math.Inf(1)
+	for i := 0; i < rows; i++ {
+		if dist := sqDist(x, m.RawRowView(i)); dist < minDist {
+			closest, minDist = i, dist
+		}
+	}
+	return closest, minDist
+}
+
+// sqDist returns squared Euclidean distance between x and y
+func sqDist(x, y []float64) float64 {
+	dist := 0.0
+	for i := range x {
+		dist += (x[i] - y[i]) * (x[i] - y[i])
+	}
+	return dist
+}
@@ -0,0 +1,68 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKMeans(t *testing.T) {
+	assert := assert.New(t)
+
+	labels, err := KMeans(blobs, 3)
+	assert.NoError(err)
+	assert.Equal(blobLabels, labels)
+	// clustering is deterministic
+	again, err := KMeans(blobs, 3)
+	assert.NoError(err)
+	assert.Equal(labels, again)
+	// single cluster
+	labels, err = KMeans(blobs, 1)
+	assert.NoError(err)
+	assert.Equal(make([]int, 12), labels)
+	// every row in its own cluster
+	labels, err = KMeans(blobs, 12)
+	assert.NoError(err)
+	assert.Equal([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, labels)
+	// identical rows
+	labels, err = KMeans(mat64.NewDense(3, 1, []float64{1, 1, 1}), 2)
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 0}, labels)
+	// less distinct rows than clusters
+	labels, err = KMeans(mat64.NewDense(4, 1, []float64{1, 1, 2, 2}), 3)
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 1, 1}, labels)
+
+	for _, k := range []int{0, -1, 13} {
+		labels, err := KMeans(blobs, k)
+		assert.Nil(labels)
+		assert.Error(err)
+	}
+	labels, err = KMeans(nil, 2)
+	assert.Nil(labels)
+	assert.Error(err)
+}
+
+func TestReseed(t *testing.T) {
+	assert := assert.New(t)
+
+	data := mat64.NewDense(3, 1, []float64{0, 1, 10})
+	labels := []int{0, 0, 0}
+	centers := mat64.NewDense(2, 1, []float64{11.0 / 3, 0})
+	sizes := []int{3, 0}
+	// the row farthest from its centroid is moved to the empty cluster
+	assert.True(reseed(data, labels, centers, sizes))
+	assert.Equal([]int{0, 0, 1}, labels)
+	assert.Equal([]int{2, 1}, sizes)
+	assert.Equal(10.0, centers.At(1, 0))
+	assert.False(reseed(data, labels, centers, sizes))
+
+	// rows which coincide with their centroids are not picked
+	data = mat64.NewDense(2, 1, []float64{1, 1})
+	labels = []int{0, 0}
+	centers = mat64.NewDense(2, 1, []float64{1, 0})
+	sizes = []int{2, 0}
+	assert.False(reseed(data, labels, centers, sizes))
+	assert.Equal([]int{0, 0}, labels)
+}
@@ -0,0 +1,93 @@
+package cluster
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// DaviesBouldin returns Davies-Bouldin index of data rows clustered according to labels.
+// The index is the mean similarity of each cluster with its most similar cluster, where
+// the similarity is the ratio of within-cluster scatters to the distance between clusters.
+// Lower values indicate better clustering. Distances are measured using Euclidean distance.
+// It returns error if data is nil, if the number of labels does not match the number of data
+// rows, if any of the labels is negative, if there are less than 2 clusters or if centroids
+// of any two clusters coincide.
+func DaviesBouldin(data *mat64.Dense, labels []int) (float64, error) {
+	k, err := validateLabels(data, labels)
+	if err != nil {
+		return 0.0, err
+	}
+	centers, sizes := centroids(data, labels, k)
+	// mean distances of rows from their cluster centroids
+	scatters := make([]float64, k)
+	for i, label := range labels {
+		scatters[label] += math.Sqrt(sqDist(data.RawRowView(i), centers.RawRowView(label)))
+	}
+	count := 0
+	for label, size := range sizes {
+		if size > 0 {
+			scatters[label] /= float64(size)
+			count++
+		}
+	}
+	db := 0.0
+	for i := 0; i < k; i++ {
+		if sizes[i] == 0 {
+			continue
+		}
+		maxSim := 0.0
+		for j := 0; j < k; j++ {
+			if j == i || sizes[j] == 0 {
+				continue
+			}
+			dist := math.Sqrt(sqDist(centers.RawRowView(i), centers.RawRowView(j)))
+			if dist == 0 {
+				return 0.0, fmt.Errorf("Coincident cluster centroids: %d, %d\n", i, j)
+			}
+			maxSim = math.Max(maxSim, (scatters[i]+scatters[j])/dist)
+		}
+		db += maxSim
+	}
+	return db / float64(count), nil
+}
+
+// Silhouette returns mean silhouette coefficient of data rows clustered according to labels.
+// Silhouette of a row compares its mean distance a from the rows in its own cluster with its
+// mean distance b from the rows in the nearest other cluster: (b - a) / max(a, b). Silhouette
+// of rows in single row clusters is 0. Values range from -1 to 1 with higher values indicating
+// better clustering. Distances are measured using Euclidean distance.
+// It returns error if data is nil, if the number of labels does not match the number of data
+// rows, if any of the labels is negative or if there are less than 2 clusters.
+func Silhouette(data *mat64.Dense, labels []int) (float64, error) {
+	k, err := validateLabels(data, labels)
+	if err != nil {
+		return 0.0, err
+	}
+	_, sizes := centroids(data, labels, k)
+	silhouette := 0.0
+	// sums of distances of a row from the rows in all clusters
+	sums := make([]float64, k)
+	for i, label := range labels {
+		for c := range sums {
+			sums[c] = 0
+		}
+		for j, other := range labels {
+			sums[other] += math.Sqrt(sqDist(data.RawRowView(i), data.RawRowView(j)))
+		}
+		if sizes[label] == 1 {
+			continue
+		}
+		a, b := sums[label]/float64(sizes[label]-1), math.Inf(1)
+		for c, sum := range sums {
+			if c != label && sizes[c] > 0 {
+				b = math.Min(b, sum/float64(sizes[c]))
+			}
+		}
+		if max := math.Max(a, b); max > 0 {
+			silhouette += (b - a) / max
+		}
+	}
+	return silhouette / float64(len(labels)), nil
+}
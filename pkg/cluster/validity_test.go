@@ -0,0 +1,84 @@
+package cluster
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaviesBouldin(t *testing.T) {
+	assert := assert.New(t)
+
+	// two clusters with scatters 1 and 2 whose centroids are 10 apart
+	data := mat64.NewDense(4, 1, []float64{-1, 1, 8, 12})
+	db, err := DaviesBouldin(data, []int{0, 0, 1, 1})
+	assert.NoError(err)
+	assert.InDelta(0.3, db, 1e-9)
+	// empty clusters are ignored
+	db, err = DaviesBouldin(data, []int{0, 0, 3, 3})
+	assert.NoError(err)
+	assert.InDelta(0.3, db, 1e-9)
+	// well separated clusters have lower index
+	good, err := DaviesBouldin(blobs, blobLabels)
+	assert.NoError(err)
+	bad, err := DaviesBouldin(blobs, []int{0, 1, 2, 0, 1, 2, 0, 1, 2, 0, 1, 2})
+	assert.NoError(err)
+	assert.True(good < bad)
+	// clusters with coincident centroids
+	for _, vals := range [][]float64{{0, 2, 1, 1}, {1, 1, 1, 1}} {
+		db, err = DaviesBouldin(mat64.NewDense(4, 1, vals), []int{0, 0, 1, 1})
+		assert.Equal(0.0, db)
+		assert.EqualError(err, "Coincident cluster centroids: 0, 1\n")
+	}
+
+	testLabelErrors(t, DaviesBouldin)
+}
+
+func TestSilhouette(t *testing.T) {
+	assert := assert.New(t)
+
+	data := mat64.NewDense(4, 1, []float64{0, 1, 10, 11})
+	s, err := Silhouette(data, []int{0, 0, 1, 1})
+	assert.NoError(err)
+	// a = 1 for all rows, b = 10.5 for the outer rows and 9.5 for the inner rows
+	exp := ((9.5-1)/9.5 + (10.5-1)/10.5) / 2
+	assert.InDelta(exp, s, 1e-9)
+	// single row clusters have zero silhouette
+	s, err = Silhouette(data, []int{0, 0, 1, 2})
+	assert.NoError(err)
+	assert.InDelta(((10.0-1)/10+(9.0-1)/9)/4, s, 1e-9)
+	// well separated clusters have higher silhouette
+	good, err := Silhouette(blobs, blobLabels)
+	assert.NoError(err)
+	bad, err := Silhouette(blobs, []int{0, 1, 2, 0, 1, 2, 0, 1, 2, 0, 1, 2})
+	assert.NoError(err)
+	assert.True(good > 0.9)
+	assert.True(bad < good)
+	assert.False(math.IsNaN(bad))
+
+	testLabelErrors(t, Silhouette)
+}
+
+// testLabelErrors checks that validity index fails with invalid labels
+func testLabelErrors(t *testing.T, fn func(*mat64.Dense, []int) (float64, error)) {
+	assert := assert.New(t)
+
+	data := mat64.NewDense(3, 1, []float64{0, 1, 2})
+	errCases := []struct {
+		data   *mat64.Dense
+		labels []int
+	}{
+		{nil, []int{0, 1, 1}},
+		{data, []int{0, 1}},
+		{data, []int{0, -1, 1}},
+		{data, []int{0, 0, 0}},
+		{data, []int{2, 2, 2}},
+	}
+	for _, tc := range errCases {
+		val, err := fn(tc.data, tc.labels)
+		assert.Equal(0.0, val)
+		assert.Error(err)
+	}
+}
@@ -4,29 +4,78 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/gonum/matrix/mat64"
 	"github.com/gonum/stat"
 )
 
+// noLabel is the label column index of unlabeled data sets
+const noLabel = -1
+
+// Missing contains data field values which denote missing values.
+// Missing values are loaded as NaN. You can register your own missing value
+// markers by adding them to this map.
+var Missing = map[string]bool{
+	"":    true,
+	"NA":  true,
+	"N/A": true,
+}
+
 // load data funcs
-var loadFuncs = map[string]func(io.Reader) (*mat64.Dense, error){
-	".csv": LoadCSV,
+var loadFuncs = map[string]func(io.Reader, int, bool) (*mat64.Dense, []string, []string, error){
+	".csv": loadCSV,
 }
 
 // DataSet represents training data set
 type DataSet struct {
 	data *mat64.Dense
+	// features contains data feature names
+	features []string
+	// labels contains data sample labels: nil if data set is not labeled
+	labels []string
+	// mean and stdev contain column means and standard deviations used to scale data:
+	// both are nil if data has not been scaled
+	mean  []float64
+	stdev []float64
 }
 
 // New returns new data set or fails with error if either the path to data set
 // supplied as a parameter does not exist or if the file is encoded
 // in an unsupported format. File format is inferred from the file extension.
-// Currently only csv files are supported.
+// Currently only csv files are supported. The file must not contain a header:
+// the features are named x1, x2 etc. Use NewWithHeader to load files with a header.
 func New(path string) (*DataSet, error) {
+	return newDataSet(path, noLabel, false)
+}
+
+// NewWithHeader returns new data set loaded in the same way as by New from a file whose
+// first record is a header which contains feature names.
+// It returns error if the data set could not be loaded.
+func NewWithHeader(path string) (*DataSet, error) {
+	return newDataSet(path, noLabel, true)
+}
+
+// NewLabeled returns new labeled data set whose data sample labels are stored in the column
+// with index labelCol. Label column is not part of the data set features. Labels can be any
+// strings. If header is true, the first record of the file is a header which contains
+// feature names. Data set is otherwise loaded in the same way as by New.
+// It returns error if the data set could not be loaded or if the label column is invalid.
+func NewLabeled(path string, labelCol int, header bool) (*DataSet, error) {
+	if labelCol < 0 {
+		return nil, fmt.Errorf("Invalid label column: %d\n", labelCol)
+	}
+	return newDataSet(path, labelCol, header)
+}
+
+// newDataSet loads data set from the path supplied as a parameter.
+// Data sample labels are read from column labelCol unless it is equal to noLabel.
+// Feature names are read from the first record of the file if header is true.
+func newDataSet(path string, labelCol int, header bool) (*DataSet, error) {
 	// Check if the supplied file type is supported
 	fileType := filepath.Ext(path)
 	loadData, ok := loadFuncs[fileType]
@@ -44,13 +93,23 @@ func New(path string) (*DataSet, error) {
 	}
 	defer file.Close()
 	// Load file
-	data, err := loadData(file)
+	data, features, labels, err := loadData(file, labelCol, header)
 	if err != nil {
 		return nil, err
 	}
+	// name the features if the file does not contain their names
+	if features == nil {
+		_, cols := data.Dims()
+		features = make([]string, cols)
+		for i := range features {
+			features[i] = fmt.Sprintf("x%d", i+1)
+		}
+	}
 	// Return Data
 	return &DataSet{
-		data: data,
+		data:     data,
+		features: features,
+		labels:   labels,
 	}, nil
 }
 
@@ -59,71 +118,197 @@ func (ds DataSet) Data() *mat64.Dense {
 	return ds.data
 }
 
+// Features returns data feature names
+func (ds DataSet) Features() []string {
+	return ds.features
+}
+
+// Labels returns data sample labels or nil if the data set is not labeled
+func (ds DataSet) Labels() []string {
+	return ds.labels
+}
+
 // Scale normalizes data in each column based on its mean and standard deviation and returns it.
 // It modifies the underlying daata. If this is not desirable use the standalone Scale function.
+// Column means and standard deviations are recorded, so the data can be transformed back
+// to original units using Unscale.
 func (ds *DataSet) Scale() *mat64.Dense {
-	return scale(ds.data, true)
+	data, mean, stdev := scale(ds.data, true)
+	// data scaled repeatedly are transformed back to the original units all at once
+	if ds.mean != nil {
+		for i := range mean {
+			mean[i] = ds.mean[i] + mean[i]*ds.stdev[i]
+			stdev[i] *= ds.stdev[i]
+		}
+	}
+	ds.mean, ds.stdev = mean, stdev
+	return data
+}
+
+// Unscale transforms data stored in rows of the supplied matrix from the units of scaled
+// data set back to the original data units. It returns a copy of the supplied matrix
+// if the data set has not been scaled. Unscale does not modify the supplied matrix.
+// It returns error if the supplied matrix is nil or if its number of columns does not
+// match the number of the data set features.
+func (ds DataSet) Unscale(mx *mat64.Dense) (*mat64.Dense, error) {
+	if mx == nil {
+		return nil, fmt.Errorf("Invalid matrix supplied: %v\n", mx)
+	}
+	_, dsCols := ds.data.Dims()
+	if _, cols := mx.Dims(); cols != dsCols {
+		return nil, fmt.Errorf("Data dimension mismatch. Expected: %d, got: %d\n", dsCols, cols)
+	}
+	dataMx := new(mat64.Dense)
+	dataMx.Clone(mx)
+	if ds.mean != nil {
+		dataMx.Apply(func(i, j int, x float64) float64 {
+			return x*ds.stdev[j] + ds.mean[j]
+		}, dataMx)
+	}
+	return dataMx, nil
 }
 
 // LoadCSV loads data set from the path supplied as a parameter.
 // It returns data matrix that contains particular CSV fields in columns.
+// Empty fields and other missing value markers registered in Missing are loaded as NaN.
 // It returns error if the supplied data set contains corrrupted data or
 // if the data can not be converted to float numbers
 func LoadCSV(r io.Reader) (*mat64.Dense, error) {
+	data, _, _, err := loadCSV(r, noLabel, false)
+	return data, err
+}
+
+// LoadCSVHeader loads data set whose first record is a header from the path supplied as
+// a parameter. It returns data matrix that contains particular CSV fields in columns
+// along with the field names read from the header.
+// It returns error if the supplied data set contains corrrupted data or
+// if the data can not be converted to float numbers
+func LoadCSVHeader(r io.Reader) (*mat64.Dense, []string, error) {
+	data, names, _, err := loadCSV(r, noLabel, true)
+	return data, names, err
+}
+
+// LoadLabeledCSV loads labeled data set from the path supplied as a parameter.
+// It returns data matrix that contains particular CSV fields in columns except for
+// the field in column labelCol which is returned as data sample labels.
+// If header is true, the first record is a header and it is skipped.
+// Missing feature values are loaded as NaN: see Missing.
+// It returns error if the supplied data set contains corrrupted data, if the data can not be
+// converted to float numbers or if the label column is invalid.
+func LoadLabeledCSV(r io.Reader, labelCol int, header bool) (*mat64.Dense, []string, error) {
+	if labelCol < 0 {
+		return nil, nil, fmt.Errorf("Invalid label column: %d\n", labelCol)
+	}
+	data, _, labels, err := loadCSV(r, labelCol, header)
+	return data, labels, err
+}
+
+// loadCSV loads data set from the path supplied as a parameter.
+// It returns data matrix that contains particular CSV fields in columns along with
+// the field names read from the first record if header is true: otherwise names are nil.
+// If labelCol is not equal to noLabel, fields in column labelCol are returned as labels
+// and they are excluded from the data matrix and names.
+// It returns error if the supplied data set contains corrrupted data or
+// if the data can not be converted to float numbers
+func loadCSV(r io.Reader, labelCol int, header bool) (*mat64.Dense, []string, []string, error) {
 	// data matrix dimensions: rows x cols
 	var rows, cols int
 	// mxData contains ALL data read field by field
 	var mxData []float64
+	// names contains CSV header fields
+	var names []string
+	// labels contains label fields
+	var labels []string
 	// create new CSV reader
 	csvReader := csv.NewReader(r)
 	// read all data record by record
-	for {
+	for first := true; ; first = false {
 		record, err := csvReader.Read()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
-		// allocate the dataRow during the first iteration
-		if rows == 0 {
-			// initialize cols on first iteration
+		// initialize cols on first iteration
+		if first {
 			cols = len(record)
+			if labelCol != noLabel {
+				if labelCol >= cols {
+					return nil, nil, nil, fmt.Errorf("Invalid label column: %d\n", labelCol)
+				}
+				cols--
+			}
+			if header {
+				names = withoutField(record, labelCol)
+				continue
+			}
 		}
 		// convert strings to floats
-		for _, field := range record {
-			f, err := strconv.ParseFloat(field, 64)
+		for i, field := range record {
+			if i == labelCol {
+				labels = append(labels, field)
+				continue
+			}
+			f, err := parseField(field)
 			if err != nil {
-				return nil, err
+				return nil, nil, nil, err
 			}
 			// append the read data into mxData
 			mxData = append(mxData, f)
 		}
 		rows++
 	}
+	// header without any data
+	if rows == 0 || cols == 0 {
+		return nil, nil, nil, fmt.Errorf("No data found\n")
+	}
 	// return data matrix
-	return mat64.NewDense(rows, cols, mxData), nil
+	return mat64.NewDense(rows, cols, mxData), names, labels, nil
+}
+
+// withoutField returns a copy of record without the field with index col
+func withoutField(record []string, col int) []string {
+	fields := make([]string, 0, len(record))
+	for i, field := range record {
+		if i != col {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// parseField converts CSV field to float number. Missing values are converted to NaN.
+// It returns error if the field is neither a number nor a missing value.
+func parseField(field string) (float64, error) {
+	if Missing[strings.TrimSpace(field)] {
+		return math.NaN(), nil
+	}
+	return strconv.ParseFloat(field, 64)
 }
 
 // Scale centers the data set to zero mean values in each column and then normalizes them.
 // It does not modify the data stored in the matrix supplied as a parameter.
 func Scale(mx mat64.Matrix) *mat64.Dense {
-	return scale(mx, false)
+	data, _, _ := scale(mx, false)
+	return data
 }
 
 // scale centers the supplied data set to zero mean in each column and then normalizes them.
-// You can specify whether you want to scale data in place or return new data set
-func scale(mx mat64.Matrix, inPlace bool) *mat64.Dense {
+// Missing values (NaN) are ignored when computing column means and standard deviations.
+// You can specify whether you want to scale data in place or return new data set.
+// It returns the scaled data along with the column means and standard deviations.
+func scale(mx mat64.Matrix, inPlace bool) (*mat64.Dense, []float64, []float64) {
 	rows, cols := mx.Dims()
 	// mean/stdev store each column mean/stdev values
 	col := make([]float64, rows)
 	mean := make([]float64, cols)
 	stdev := make([]float64, cols)
-	// calculate mean and standard deviation for each column
+	// calculate mean and standard deviation of observed values in each column
 	for i := 0; i < cols; i++ {
 		// copy i-th column to col
 		mat64.Col(col, i, mx)
-		mean[i], stdev[i] = stat.MeanStdDev(col, nil)
+		mean[i], stdev[i] = stat.MeanStdDev(observed(col), nil)
 	}
 	// initialize scale function
 	scale := func(i, j int, x float64) float64 {
@@ -133,11 +318,22 @@ func scale(mx mat64.Matrix, inPlace bool) *mat64.Dense {
 	if inPlace {
 		mxDense := mx.(*mat64.Dense)
 		mxDense.Apply(scale, mxDense)
-		return mxDense
+		return mxDense, mean, stdev
 	}
 	// otherwise allocate new data matrix
 	dataMx := new(mat64.Dense)
 	dataMx.Clone(mx)
 	dataMx.Apply(scale, dataMx)
-	return dataMx
+	return dataMx, mean, stdev
+}
+
+// observed returns the values of x which are not missing (NaN)
+func observed(x []float64) []float64 {
+	vals := make([]float64, 0, len(x))
+	for _, v := range x {
+		if !math.IsNaN(v) {
+			vals = append(vals, v)
+		}
+	}
+	return vals
 }
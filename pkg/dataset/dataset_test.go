@@ -3,6 +3,7 @@ package dataset
 import (
 	"io/ioutil"
 	"log"
+	"math"
 	"os"
 	"path"
 	"path/filepath"
@@ -102,6 +103,64 @@ func TestLoadCSV(t *testing.T) {
 	assert.Nil(mx)
 }
 
+func TestLoadCSVMissing(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		data    string
+		missing [][2]int
+	}{
+		{"1,,3\n4,5,6", [][2]int{{0, 1}}},
+		{"1,NA,3\nN/A,5, NA ", [][2]int{{0, 1}, {1, 0}, {1, 2}}},
+		{"1,2,\n4,5,6", [][2]int{{0, 2}}},
+		{"NaN,2,3\n4,5,6", [][2]int{{0, 0}}},
+	}
+	for _, tc := range testCases {
+		mx, err := LoadCSV(strings.NewReader(tc.data))
+		assert.NoError(err, tc.data)
+		rows, cols := mx.Dims()
+		assert.Equal(2, rows)
+		assert.Equal(3, cols)
+		count := 0
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				if math.IsNaN(mx.At(i, j)) {
+					count++
+				}
+			}
+		}
+		assert.Equal(len(tc.missing), count, tc.data)
+		for _, pos := range tc.missing {
+			assert.True(math.IsNaN(mx.At(pos[0], pos[1])), tc.data)
+		}
+	}
+}
+
+func TestScaleMissing(t *testing.T) {
+	assert := assert.New(t)
+
+	nan := math.NaN()
+	mx := mat64.NewDense(4, 2, []float64{
+		2.0, nan,
+		4.5, 3.5,
+		7.0, 5.5,
+		nan, 9.0,
+	})
+	scaled := Scale(mx)
+	// missing values stay missing
+	assert.True(math.IsNaN(scaled.At(0, 1)))
+	assert.True(math.IsNaN(scaled.At(3, 0)))
+	// observed values are scaled using statistics of observed values
+	expected := []float64{
+		-1, -0.8980265101338746,
+		0, -0.1796053020267749,
+		1, 1.0776318121606494,
+	}
+	for i, v := range []float64{scaled.At(0, 0), scaled.At(1, 1), scaled.At(1, 0), scaled.At(2, 1), scaled.At(2, 0), scaled.At(3, 1)} {
+		assert.InDelta(expected[i], v, 1e-9)
+	}
+}
+
 func TestScale(t *testing.T) {
 	assert := assert.New(t)
 
@@ -120,3 +179,182 @@ func TestScale(t *testing.T) {
 	scaledDs := Scale(ds.Data())
 	assert.True(mat64.Equal(scaledDs, scaledMx))
 }
+
+func TestFeatures(t *testing.T) {
+	assert := assert.New(t)
+
+	// data set without header
+	ds, err := New(path.Join(os.TempDir(), fileName))
+	assert.NoError(err)
+	assert.Equal([]string{"x1", "x2"}, ds.Features())
+
+	// data set with header
+	tmpPath := filepath.Join(os.TempDir(), "header.csv")
+	err = ioutil.WriteFile(tmpPath, []byte("length,width\n2.0,3.5\n4.5,5.5\n7.0,9.0"), 0666)
+	assert.NoError(err)
+	defer os.Remove(tmpPath)
+	ds, err = NewWithHeader(tmpPath)
+	assert.NoError(err)
+	assert.Equal([]string{"length", "width"}, ds.Features())
+	rows, cols := ds.Data().Dims()
+	assert.Equal(3, rows)
+	assert.Equal(2, cols)
+}
+
+func TestLoadCSVHeader(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		data   string
+		rows   int
+		names  []string
+		expErr bool
+	}{
+		{"a,b\n1,2\n3,4", 2, []string{"a", "b"}, false},
+		{"a,2\n1,2", 1, []string{"a", "2"}, false},
+		// header made of missing value markers
+		{"NA,\n1,2", 1, []string{"NA", ""}, false},
+		// header only
+		{"a,b", 0, nil, true},
+		// second header
+		{"a,b\nc,d\n1,2", 0, nil, true},
+		// header length mismatch
+		{"a,b,c\n1,2", 0, nil, true},
+	}
+
+	for _, tc := range testCases {
+		mx, names, err := LoadCSVHeader(strings.NewReader(tc.data))
+		if tc.expErr {
+			assert.Error(err, tc.data)
+			assert.Nil(mx)
+			assert.Nil(names)
+			continue
+		}
+		assert.NoError(err)
+		rows, _ := mx.Dims()
+		assert.Equal(tc.rows, rows)
+		assert.Equal(tc.names, names)
+	}
+
+	// files without header can't contain non-numeric fields
+	for _, data := range []string{"a,b\n1,2", "a,2\n1,2", "1,2\na,2"} {
+		mx, err := LoadCSV(strings.NewReader(data))
+		assert.Nil(mx)
+		assert.Error(err, data)
+	}
+	// header made of missing value markers is loaded as data without header
+	mx, err := LoadCSV(strings.NewReader("NA,\n1,2"))
+	assert.NoError(err)
+	rows, _ := mx.Dims()
+	assert.Equal(2, rows)
+}
+
+func TestUnscale(t *testing.T) {
+	assert := assert.New(t)
+
+	ds, err := New(path.Join(os.TempDir(), fileName))
+	assert.NoError(err)
+	orig := new(mat64.Dense)
+	orig.Clone(ds.Data())
+	// unscaled data set
+	unscaled, err := ds.Unscale(orig)
+	assert.NoError(err)
+	assert.True(mat64.Equal(orig, unscaled))
+	// scaled data set
+	scaled := ds.Scale()
+	unscaled, err = ds.Unscale(scaled)
+	assert.NoError(err)
+	assert.True(mat64.EqualApprox(orig, unscaled, 1e-9))
+	// Unscale does not modify its input
+	assert.True(mat64.Equal(scaled, ds.Data()))
+	// data set scaled twice
+	scaled = ds.Scale()
+	unscaled, err = ds.Unscale(scaled)
+	assert.NoError(err)
+	assert.True(mat64.EqualApprox(orig, unscaled, 1e-9))
+
+	unscaled, err = ds.Unscale(nil)
+	assert.Nil(unscaled)
+	assert.Error(err)
+	unscaled, err = ds.Unscale(mat64.NewDense(1, 3, nil))
+	assert.Nil(unscaled)
+	assert.Error(err)
+}
+
+func TestLoadLabeledCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		data     string
+		labelCol int
+		rows     int
+		cols     int
+		header   bool
+		labels   []string
+		expErr   bool
+	}{
+		{"1,2,setosa\n3,4,virginica", 2, 2, 2, false, []string{"setosa", "virginica"}, false},
+		{"a,b,species\n1,2,setosa\n3,4,virginica", 2, 2, 2, true, []string{"setosa", "virginica"}, false},
+		{"1,A,2\n3,B,4", 1, 2, 2, false, []string{"A", "B"}, false},
+		{"1,2\n3,4", 0, 2, 1, false, []string{"1", "3"}, false},
+		// label column out of range
+		{"1,2\n3,4", 2, 0, 0, false, nil, true},
+		{"1,2\n3,4", -1, 0, 0, false, nil, true},
+		// non-numeric feature
+		{"1,2,a\n3,x,b", 2, 0, 0, false, nil, true},
+		{"1,x,a\n3,4,b", 2, 0, 0, false, nil, true},
+		// header not requested
+		{"a,b,species\n1,2,setosa", 2, 0, 0, false, nil, true},
+		// label column only
+		{"a\nb", 0, 0, 0, false, nil, true},
+	}
+
+	for _, tc := range testCases {
+		mx, labels, err := LoadLabeledCSV(strings.NewReader(tc.data), tc.labelCol, tc.header)
+		if tc.expErr {
+			assert.Error(err, tc.data)
+			assert.Nil(mx)
+			assert.Nil(labels)
+			continue
+		}
+		assert.NoError(err)
+		rows, cols := mx.Dims()
+		assert.Equal(tc.rows, rows)
+		assert.Equal(tc.cols, cols)
+		assert.Equal(tc.labels, labels)
+	}
+}
+
+func TestNewLabeled(t *testing.T) {
+	assert := assert.New(t)
+
+	tmpPath := filepath.Join(os.TempDir(), "labeled.csv")
+	content := "length,species,width\n2.0,setosa,3.5\n4.5,virginica,5.5\n7.0,setosa,9.0"
+	assert.NoError(ioutil.WriteFile(tmpPath, []byte(content), 0666))
+	defer os.Remove(tmpPath)
+
+	ds, err := NewLabeled(tmpPath, 1, true)
+	assert.NoError(err)
+	assert.Equal([]string{"length", "width"}, ds.Features())
+	assert.Equal([]string{"setosa", "virginica", "setosa"}, ds.Labels())
+	assert.True(mat64.Equal(mat64.NewDense(3, 2, []float64{2.0, 3.5, 4.5, 5.5, 7.0, 9.0}), ds.Data()))
+
+	// header must be requested explicitly
+	ds, err = NewLabeled(tmpPath, 1, false)
+	assert.Nil(ds)
+	assert.Error(err)
+	// unlabeled data set can't be loaded because of the label column
+	ds, err = NewWithHeader(tmpPath)
+	assert.Nil(ds)
+	assert.Error(err)
+	// unlabeled data set has no labels
+	ds, err = New(path.Join(os.TempDir(), fileName))
+	assert.NoError(err)
+	assert.Nil(ds.Labels())
+
+	for _, labelCol := range []int{-1, 3} {
+		ds, err = NewLabeled(tmpPath, labelCol, true)
+		assert.Nil(ds)
+		assert.Error(err)
+	}
+}
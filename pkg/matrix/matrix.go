@@ -2,34 +2,40 @@ package matrix
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 
+	"github.com/gonum/floats"
 	"github.com/gonum/matrix/mat64"
 	"github.com/gonum/stat"
 )
 
-// ColsMax returns a slice of max values of first cols number of matrix columns
+// ColsMax returns a slice of max values of first cols number of matrix columns.
+// Missing values (NaN) are ignored: the result is NaN if there are no other values.
 // It returns error if passed in matrix is nil, has zero size or requested number
 // of columns exceeds the number of columns in the matrix passed in as parameter.
 func ColsMax(cols int, m *mat64.Dense) ([]float64, error) {
-	return withValidDim("cols", cols, m, mat64.Max)
+	return withValidDim("cols", cols, m, maxVal)
 }
 
-// ColsMin returns a slice of min values of first cols number of matrix columns
+// ColsMin returns a slice of min values of first cols number of matrix columns.
+// Missing values (NaN) are ignored: the result is NaN if there are no other values.
 // It returns error if passed in matrix is nil, has zero size or requested number
 // of columns exceeds the number of columns in the matrix passed in as parameter.
 func ColsMin(cols int, m *mat64.Dense) ([]float64, error) {
-	return withValidDim("cols", cols, m, mat64.Min)
+	return withValidDim("cols", cols, m, minVal)
 }
 
-// ColsMean returns a slice of mean values of first cols matrix columns
+// ColsMean returns a slice of mean values of first cols matrix columns.
+// Missing values (NaN) are ignored: the result is NaN if there are no other values.
 // It returns error if passed in matrix is nil or has zero size or requested number
 // of columns exceeds the number of columns in matrix m.
 func ColsMean(cols int, m *mat64.Dense) ([]float64, error) {
 	return withValidDim("cols", cols, m, mean)
 }
 
-// ColsStdev returns a slice of standard deviations of first cols matrix columns
+// ColsStdev returns a slice of standard deviations of first cols matrix columns.
+// Missing values (NaN) are ignored: the result is NaN if there are no other values.
 // It returns error if passed in matrix is nil or has zero size or requested number
 // of columns exceeds the number of columns in matrix m.
 func ColsStdev(cols int, m *mat64.Dense) ([]float64, error) {
@@ -37,17 +43,19 @@ func ColsStdev(cols int, m *mat64.Dense) ([]float64, error) {
 }
 
 // RowsMax returns a slice of max values of first rows matrix rows.
+// Missing values (NaN) are ignored: the result is NaN if there are no other values.
 // It returns error if passed in matrix is nil or has zero size or requested number
 // of rows exceeds the number of rows in matrix m.
 func RowsMax(rows int, m *mat64.Dense) ([]float64, error) {
-	return withValidDim("rows", rows, m, mat64.Max)
+	return withValidDim("rows", rows, m, maxVal)
 }
 
 // RowsMin returns a slice of min values of first rows matrix rows.
+// Missing values (NaN) are ignored: the result is NaN if there are no other values.
 // It returns error if passed in matrix is nil or has zero size or requested number
 // of rows exceeds the number of rows in matrix m.
 func RowsMin(rows int, m *mat64.Dense) ([]float64, error) {
-	return withValidDim("rows", rows, m, mat64.Min)
+	return withValidDim("rows", rows, m, minVal)
 }
 
 // MakeRandom creates a new matrix with provided number of rows and columns
@@ -165,16 +173,48 @@ func withValidDims(rows, cols int, fn func() (*mat64.Dense, error)) (*mat64.Dens
 	return fn()
 }
 
-// returns a mean valur for a given matrix
+// returns max value of a given matrix ignoring missing values
+func maxVal(m mat64.Matrix) float64 {
+	vals := observed(m)
+	if len(vals) == 0 {
+		return math.NaN()
+	}
+	return floats.Max(vals)
+}
+
+// returns min value of a given matrix ignoring missing values
+func minVal(m mat64.Matrix) float64 {
+	vals := observed(m)
+	if len(vals) == 0 {
+		return math.NaN()
+	}
+	return floats.Min(vals)
+}
+
+// returns a mean value for a given matrix ignoring missing values
 func mean(m mat64.Matrix) float64 {
-	r, c := m.Dims()
-	return mat64.Sum(m) / (float64(r) * float64(c))
+	vals := observed(m)
+	if len(vals) == 0 {
+		return math.NaN()
+	}
+	return floats.Sum(vals) / float64(len(vals))
 }
 
-// returns a mean valur for a given matrix
+// returns a standard deviation of a given column matrix ignoring missing values
 func stdev(m mat64.Matrix) float64 {
-	r, _ := m.Dims()
-	col := make([]float64, r)
-	mat64.Col(col, 0, m)
-	return stat.StdDev(col, nil)
+	return stat.StdDev(observed(m), nil)
+}
+
+// returns the values of a given matrix which are not missing (NaN) in row-major order
+func observed(m mat64.Matrix) []float64 {
+	r, c := m.Dims()
+	vals := make([]float64, 0, r*c)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			if v := m.At(i, j); !math.IsNaN(v) {
+				vals = append(vals, v)
+			}
+		}
+	}
+	return vals
 }
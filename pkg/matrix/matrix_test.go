@@ -2,6 +2,7 @@ package matrix
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/gonum/floats"
@@ -145,6 +146,41 @@ func TestColsStdev(t *testing.T) {
 	assert.True(floats.EqualApprox(colsStdev, sd, 0.01))
 }
 
+func TestMissingValues(t *testing.T) {
+	assert := assert.New(t)
+
+	nan := math.NaN()
+	data := []float64{
+		1.2, nan,
+		nan, nan,
+		8.9, 10.0,
+		4.5, 6.0,
+	}
+	mx := mat64.NewDense(4, 2, data)
+
+	max, err := ColsMax(2, mx)
+	assert.NoError(err)
+	assert.EqualValues([]float64{8.9, 10.0}, max)
+	min, err := ColsMin(2, mx)
+	assert.NoError(err)
+	assert.EqualValues([]float64{1.2, 6.0}, min)
+	me, err := ColsMean(2, mx)
+	assert.NoError(err)
+	assert.True(floats.EqualApprox([]float64{4.8667, 8.0}, me, 0.01))
+	sd, err := ColsStdev(2, mx)
+	assert.NoError(err)
+	assert.True(floats.EqualApprox([]float64{3.8631, 2.8284}, sd, 0.01))
+	// statistics of rows with no values are NaN
+	max, err = RowsMax(4, mx)
+	assert.NoError(err)
+	assert.Equal(1.2, max[0])
+	assert.True(math.IsNaN(max[1]))
+	min, err = RowsMin(4, mx)
+	assert.NoError(err)
+	assert.Equal(8.9, min[2])
+	assert.True(math.IsNaN(min[1]))
+}
+
 func TestMakeRandom(t *testing.T) {
 	assert := assert.New(t)
 
@@ -57,3 +57,11 @@ func IntCumProduct(vector []int) []int {
 	}
 	return cumProd
 }
+
+// IntAbs returns absolute value of integer x
+func IntAbs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
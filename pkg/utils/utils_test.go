@@ -67,3 +67,20 @@ func TestCumProduct(t *testing.T) {
 		assert.EqualValues(p, tc.expected)
 	}
 }
+
+func TestIntAbs(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		x        int
+		expected int
+	}{
+		{-3, 3},
+		{0, 0},
+		{5, 5},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(tc.expected, IntAbs(tc.x))
+	}
+}
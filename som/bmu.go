@@ -0,0 +1,269 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// BMU returns the index of the Best Match Unit (BMU) of vector vec and its distance from vec.
+// BMU is the map unit whose codebook vector is closest to vec in terms of the distance metric
+// set in map configuration. Euclidean distances are computed via matrix multiplication
+// using precomputed codebook vector norms.
+// It returns error if vec is nil or if its dimension does not match the codebook dimension.
+func (m Map) BMU(vec *mat64.Vector) (int, float64, error) {
+	row, err := vecToRow(vec)
+	if err != nil {
+		return -1, 0.0, err
+	}
+	bmus, dists, err := m.closestUnits(row, sqNorms(m.codebook))
+	if err != nil {
+		return -1, 0.0, err
+	}
+	return bmus[0], dists[0], nil
+}
+
+// BMUsFor returns indices of the Best Match Units (BMUs) of all vectors stored in rows of
+// data matrix along with their distances from the BMUs.
+// Distances are computed in blocks of data samples using the map distance metric.
+// It returns error if data is nil or if its dimension does not match the codebook dimension.
+func (m Map) BMUsFor(data *mat64.Dense) ([]int, []float64, error) {
+	return m.closestUnits(data, sqNorms(m.codebook))
+}
+
+// KBMUs returns indices of the k best matching units of vector vec ordered by their
+// distance from vec along with the distances. The first returned unit is the BMU,
+// the second one is the second BMU and so on.
+// It returns error if vec is nil, its dimension does not match the codebook dimension or
+// if k is not positive or it exceeds the number of map units.
+func (m Map) KBMUs(vec *mat64.Vector, k int) ([]int, []float64, error) {
+	mUnits, _ := m.codebook.Dims()
+	if k <= 0 || k > mUnits {
+		return nil, nil, fmt.Errorf("Invalid number of units requested: %d\n", k)
+	}
+	row, err := vecToRow(vec)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := validateDims(row, m.codebook); err != nil {
+		return nil, nil, err
+	}
+	distsMx, err := m.unitDists(row, sqNorms(m.codebook))
+	if err != nil {
+		return nil, nil, err
+	}
+	dists := mat64.Row(nil, 0, distsMx)
+	if len(m.layers) < 2 && m.config.Metric == "euclidean" {
+		for i := range dists {
+			dists[i] = math.Sqrt(dists[i])
+		}
+	}
+	units := make([]int, mUnits)
+	for i := range units {
+		units[i] = i
+	}
+	sort.Stable(byDist{units: units, dists: dists})
+	kDists := make([]float64, k)
+	for i := 0; i < k; i++ {
+		kDists[i] = dists[units[i]]
+	}
+	return units[:k], kDists, nil
+}
+
+// byDist sorts unit indices by their distances
+type byDist struct {
+	units []int
+	dists []float64
+}
+
+func (b byDist) Len() int           { return len(b.units) }
+func (b byDist) Swap(i, j int)      { b.units[i], b.units[j] = b.units[j], b.units[i] }
+func (b byDist) Less(i, j int) bool { return b.dists[b.units[i]] < b.dists[b.units[j]] }
+
+// bmuBlockSize is the number of data samples whose distances from codebook vectors
+// are computed at once when searching for their BMUs
+const bmuBlockSize = 256
+
+// closestRows returns indices of the rows in codebook matrix which are closest to the rows
+// of data matrix in terms of the requested distance metric along with the distances.
+// Distances are computed in blocks of data rows. For euclidean and sqeuclidean metrics
+// squared distances are computed as ||x||^2 - 2*X*W^T + ||w||^2, where cbNorms must contain
+// squared Euclidean norms of the codebook rows; cbNorms are ignored for other metrics.
+// It returns error if either of the matrices is nil, if their dimensions don't match
+// or if the requested metric is not supported.
+func closestRows(metric string, data, codebook *mat64.Dense, cbNorms []float64) ([]int, []float64, error) {
+	if err := validateDims(data, codebook); err != nil {
+		return nil, nil, err
+	}
+	closest, dists, err := closestBlocks(data, func(block *mat64.Dense) (*mat64.Dense, error) {
+		return distMx(metric, block, codebook, cbNorms)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if metric == "euclidean" {
+		for i := range dists {
+			dists[i] = math.Sqrt(dists[i])
+		}
+	}
+	return closest, dists, nil
+}
+
+// closestBlocks returns indices of the columns of distance matrices computed by distFn for blocks
+// of data rows which contain the smallest distance in each row along with the distances.
+// It returns error if any of the distance matrices could not be computed.
+func closestBlocks(data *mat64.Dense, distFn func(*mat64.Dense) (*mat64.Dense, error)) ([]int, []float64, error) {
+	rows, _ := data.Dims()
+	closest := make([]int, rows)
+	dists := make([]float64, rows)
+	err := forBlocks(data, distFn, func(from int, blockDists *mat64.Dense) {
+		size, _ := blockDists.Dims()
+		for i := 0; i < size; i++ {
+			row := blockDists.RawRowView(i)
+			best := 0
+			for j := 1; j < len(row); j++ {
+				if row[j] < row[best] {
+					best = j
+				}
+			}
+			closest[from+i] = best
+			dists[from+i] = row[best]
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return closest, dists, nil
+}
+
+// blockDists computes distances between blocks of data rows and codebook rows using distMx
+// and calls fn with the index of the first row in the block and the block distance matrix.
+// It returns error if either of the matrices is nil, if their dimensions don't match
+// or if the requested metric is not supported.
+func blockDists(metric string, data, codebook *mat64.Dense, cbNorms []float64, fn func(int, *mat64.Dense)) error {
+	if err := validateDims(data, codebook); err != nil {
+		return err
+	}
+	return forBlocks(data, func(block *mat64.Dense) (*mat64.Dense, error) {
+		return distMx(metric, block, codebook, cbNorms)
+	}, fn)
+}
+
+// forBlocks splits data rows into blocks of bmuBlockSize rows, computes distance matrix
+// of every block using distFn and calls fn with the index of the first row in the block
+// and the block distance matrix.
+// It returns error if any of the distance matrices could not be computed.
+func forBlocks(data *mat64.Dense, distFn func(*mat64.Dense) (*mat64.Dense, error), fn func(int, *mat64.Dense)) error {
+	rows, cols := data.Dims()
+	for from := 0; from < rows; from += bmuBlockSize {
+		size := bmuBlockSize
+		if from+size > rows {
+			size = rows - from
+		}
+		block := data.View(from, 0, size, cols).(*mat64.Dense)
+		dists, err := distFn(block)
+		if err != nil {
+			return err
+		}
+		fn(from, dists)
+	}
+	return nil
+}
+
+// distMx returns a matrix of distances between rows of data matrix and rows of codebook matrix
+// computed using the requested metric. For both euclidean and sqeuclidean metrics it returns
+// squared Euclidean distances computed via matrix multiplication using the squared norms
+// of the codebook rows stored in cbNorms. Data rows with missing values (NaN) are compared
+// with codebook rows using only their observed features: see observedDists.
+// It returns error if the requested metric is not supported.
+func distMx(metric string, data, codebook *mat64.Dense, cbNorms []float64) (*mat64.Dense, error) {
+	squared := metric == "euclidean" || metric == "sqeuclidean"
+	distFn, ok := metricFunc(metric)
+	if !ok && !squared {
+		return nil, fmt.Errorf("Unsupported distance metric: %s\n", metric)
+	}
+	rows, _ := data.Dims()
+	mUnits, _ := codebook.Dims()
+	var dists *mat64.Dense
+	if squared {
+		dists = sqDistMx(data, codebook, cbNorms)
+	} else {
+		dists = mat64.NewDense(rows, mUnits, nil)
+	}
+	for i := 0; i < rows; i++ {
+		if x := data.RawRowView(i); hasMissing(x) {
+			if err := observedDists(metric, x, codebook, dists.RawRowView(i)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if squared {
+			continue
+		}
+		for j := 0; j < mUnits; j++ {
+			dist, err := distFn(data.RowView(i), codebook.RowView(j))
+			if err != nil {
+				return nil, err
+			}
+			dists.Set(i, j, dist)
+		}
+	}
+	return dists, nil
+}
+
+// sqDistMx returns a matrix of squared Euclidean distances between rows of data matrix
+// and rows of codebook matrix. cbNorms must contain squared norms of the codebook rows.
+// Negative distances caused by rounding errors are clipped to zero.
+func sqDistMx(data, codebook *mat64.Dense, cbNorms []float64) *mat64.Dense {
+	rows, _ := data.Dims()
+	sqDists := new(mat64.Dense)
+	sqDists.Mul(data, codebook.T())
+	for i := 0; i < rows; i++ {
+		x := data.RawRowView(i)
+		xNorm := floats.Dot(x, x)
+		row := sqDists.RawRowView(i)
+		for j := range row {
+			row[j] = math.Max(xNorm-2*row[j]+cbNorms[j], 0.0)
+		}
+	}
+	return sqDists
+}
+
+// sqNorms returns a slice which contains squared Euclidean norms of matrix rows
+func sqNorms(m *mat64.Dense) []float64 {
+	rows, _ := m.Dims()
+	norms := make([]float64, rows)
+	for i := range norms {
+		row := m.RawRowView(i)
+		norms[i] = floats.Dot(row, row)
+	}
+	return norms
+}
+
+// vecToRow copies vector v into a single row matrix.
+// It returns error if v is nil.
+func vecToRow(v *mat64.Vector) (*mat64.Dense, error) {
+	if v == nil {
+		return nil, fmt.Errorf("Invalid vector supplied: %v\n", v)
+	}
+	return mat64.NewDense(1, v.Len(), mat64.Col(nil, 0, v)), nil
+}
+
+// validateDims checks whether the data vectors can be compared with codebook vectors.
+// It returns error if either of the matrices is nil or if their column counts differ.
+func validateDims(data, codebook *mat64.Dense) error {
+	if data == nil {
+		return fmt.Errorf("Invalid data matrix: %v\n", data)
+	}
+	if codebook == nil {
+		return fmt.Errorf("Invalid codebook matrix: %v\n", codebook)
+	}
+	_, cols := data.Dims()
+	if _, cbCols := codebook.Dims(); cols != cbCols {
+		return fmt.Errorf("Data dimension mismatch. Expected: %d, got: %d\n", cbCols, cols)
+	}
+	return nil
+}
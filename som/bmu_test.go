@@ -0,0 +1,246 @@
+package som
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/milosgajdos83/gosom/pkg/matrix"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestMap returns a map with a fixed codebook
+func newTestMap() *Map {
+	return &Map{
+		codebook: mat64.NewDense(4, 2, []float64{
+			0.0, 0.0,
+			0.0, 1.0,
+			1.0, 0.0,
+			1.0, 1.0,
+		}),
+		config: Config{Metric: "euclidean"},
+	}
+}
+
+func TestBMU(t *testing.T) {
+	assert := assert.New(t)
+
+	m := newTestMap()
+	testCases := []struct {
+		vec     []float64
+		expBmu  int
+		expDist float64
+	}{
+		{[]float64{0.1, 0.1}, 0, 0.1414},
+		{[]float64{0.2, 0.9}, 1, 0.2236},
+		{[]float64{2.0, 0.0}, 2, 1.0},
+		{[]float64{1.0, 1.0}, 3, 0.0},
+	}
+
+	for _, tc := range testCases {
+		bmu, dist, err := m.BMU(mat64.NewVector(len(tc.vec), tc.vec))
+		assert.NoError(err)
+		assert.Equal(tc.expBmu, bmu)
+		assert.InDelta(tc.expDist, dist, 0.001)
+	}
+	// nil vector
+	_, _, err := m.BMU(nil)
+	assert.Error(err)
+	// dimension mismatch
+	_, _, err = m.BMU(mat64.NewVector(3, []float64{1, 2, 3}))
+	assert.Error(err)
+}
+
+func TestBMUMetric(t *testing.T) {
+	assert := assert.New(t)
+
+	m := newTestMap()
+	vec := mat64.NewVector(2, []float64{0.2, 0.9})
+	for metric := range Metric {
+		m.config.Metric = metric
+		bmu, dist, err := m.BMU(vec)
+		assert.NoError(err)
+		expDist, err := Distance(metric, vec, m.codebook.RowView(bmu))
+		assert.NoError(err)
+		assert.InDelta(expDist, dist, 1e-9, metric)
+		// no other unit is closer
+		for i := 0; i < 4; i++ {
+			d, _ := Distance(metric, vec, m.codebook.RowView(i))
+			assert.True(dist <= d+1e-9, metric)
+		}
+		units, dists, err := m.KBMUs(vec, 2)
+		assert.NoError(err)
+		assert.Equal(bmu, units[0], metric)
+		assert.InDelta(dist, dists[0], 1e-9, metric)
+	}
+	// unsupported metric
+	m.config.Metric = "foobar"
+	_, _, err := m.BMU(vec)
+	assert.Error(err)
+	_, _, err = m.KBMUs(vec, 2)
+	assert.Error(err)
+}
+
+func TestBMUsFor(t *testing.T) {
+	assert := assert.New(t)
+
+	m := newTestMap()
+	data := mat64.NewDense(3, 2, []float64{
+		0.1, 0.1,
+		0.2, 0.9,
+		1.0, 1.0,
+	})
+	bmus, dists, err := m.BMUsFor(data)
+	assert.NoError(err)
+	assert.Equal([]int{0, 1, 3}, bmus)
+	for i, expDist := range []float64{0.1414, 0.2236, 0.0} {
+		assert.InDelta(expDist, dists[i], 0.001)
+	}
+	// nil data
+	bmus, dists, err = m.BMUsFor(nil)
+	assert.Nil(bmus)
+	assert.Nil(dists)
+	assert.Error(err)
+	// dimension mismatch
+	bmus, dists, err = m.BMUsFor(mat64.NewDense(1, 3, []float64{1, 2, 3}))
+	assert.Nil(bmus)
+	assert.Nil(dists)
+	assert.Error(err)
+}
+
+func TestKBMUs(t *testing.T) {
+	assert := assert.New(t)
+
+	m := newTestMap()
+	vec := mat64.NewVector(2, []float64{0.2, 0.9})
+	units, dists, err := m.KBMUs(vec, 2)
+	assert.NoError(err)
+	assert.Equal([]int{1, 3}, units)
+	assert.InDelta(0.2236, dists[0], 0.001)
+	assert.InDelta(0.8062, dists[1], 0.001)
+	// all units
+	units, dists, err = m.KBMUs(vec, 4)
+	assert.NoError(err)
+	assert.Equal([]int{1, 3, 0, 2}, units)
+	assert.Len(dists, 4)
+	// first BMU matches BMU
+	bmu, dist, err := m.BMU(vec)
+	assert.NoError(err)
+	assert.Equal(bmu, units[0])
+	assert.Equal(dist, dists[0])
+	// invalid k
+	for _, k := range []int{0, 5} {
+		units, dists, err = m.KBMUs(vec, k)
+		assert.Nil(units)
+		assert.Nil(dists)
+		assert.Error(err)
+	}
+	// nil vector
+	_, _, err = m.KBMUs(nil, 2)
+	assert.Error(err)
+}
+
+// closestVecs finds BMUs of data rows by computing euclidean distances vector by vector
+func closestVecs(data, codebook *mat64.Dense) ([]int, []float64) {
+	rows, _ := data.Dims()
+	mUnits, _ := codebook.Dims()
+	bmus := make([]int, rows)
+	dists := make([]float64, rows)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < mUnits; j++ {
+			dist, _ := Distance("euclidean", data.RowView(i), codebook.RowView(j))
+			if j == 0 || dist < dists[i] {
+				bmus[i], dists[i] = j, dist
+			}
+		}
+	}
+	return bmus, dists
+}
+
+func TestClosestRows(t *testing.T) {
+	assert := assert.New(t)
+
+	// more rows than a single block
+	data, err := matrix.MakeRandom(bmuBlockSize+10, 5, -1.0, 1.0)
+	assert.NoError(err)
+	codebook, err := matrix.MakeRandom(20, 5, -2.0, 2.0)
+	assert.NoError(err)
+	bmus, dists, err := closestRows("euclidean", data, codebook, sqNorms(codebook))
+	assert.NoError(err)
+	expBmus, expDists := closestVecs(data, codebook)
+	assert.Equal(expBmus, bmus)
+	for i := range expDists {
+		assert.InDelta(expDists[i], dists[i], 1e-9)
+	}
+	// nil data
+	_, _, err = closestRows("euclidean", nil, codebook, nil)
+	assert.Error(err)
+	// nil codebook
+	_, _, err = closestRows("euclidean", data, nil, nil)
+	assert.Error(err)
+}
+
+func TestBMUsForMissing(t *testing.T) {
+	assert := assert.New(t)
+
+	m := newTestMap()
+	nan := math.NaN()
+	data := mat64.NewDense(3, 2, []float64{
+		nan, 0.9,
+		0.8, nan,
+		0.2, 0.1,
+	})
+	testCases := []struct {
+		metric string
+		dists  []float64
+	}{
+		{"euclidean", []float64{0.1, 0.2, 0.2236068}},
+		{"sqeuclidean", []float64{0.01, 0.04, 0.05}},
+		{"manhattan", []float64{0.1, 0.2, 0.3}},
+		{"chebyshev", []float64{0.1, 0.2, 0.2}},
+		{"minkowski:1", []float64{0.1, 0.2, 0.3}},
+	}
+	for _, tc := range testCases {
+		m.config.Metric = tc.metric
+		bmus, dists, err := m.BMUsFor(data)
+		assert.NoError(err)
+		assert.Equal([]int{1, 2, 0}, bmus)
+		for i := range tc.dists {
+			assert.InDelta(tc.dists[i], dists[i], 1e-6)
+		}
+	}
+	// data samples without observed features are equally distant from all units
+	m.config.Metric = "euclidean"
+	bmus, dists, err := m.BMUsFor(mat64.NewDense(1, 2, []float64{nan, nan}))
+	assert.NoError(err)
+	assert.Equal([]int{0}, bmus)
+	assert.Equal([]float64{0.0}, dists)
+}
+
+func benchmarkData(b *testing.B) (*mat64.Dense, *mat64.Dense) {
+	data, err := matrix.MakeRandom(1000, 10, -1.0, 1.0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	codebook, err := matrix.MakeRandom(400, 10, -1.0, 1.0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return data, codebook
+}
+
+func BenchmarkBMUsEuclideanVec(b *testing.B) {
+	data, codebook := benchmarkData(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		closestVecs(data, codebook)
+	}
+}
+
+func BenchmarkBMUsMul(b *testing.B) {
+	data, codebook := benchmarkData(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		closestRows("euclidean", data, codebook, sqNorms(codebook))
+	}
+}
@@ -0,0 +1,94 @@
+package som
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// Classifier assigns class labels to data samples stored in rows of data matrix
+type Classifier interface {
+	// Classify returns class labels of the data samples stored in rows of data matrix
+	Classify(data *mat64.Dense) ([]string, error)
+}
+
+// Accuracy returns the share of the data samples stored in rows of data matrix which
+// are classified by classifier c with the class labels stored in labels.
+// It returns error if the number of labels does not match the number of data samples,
+// if any label is empty or if the data samples could not be classified.
+func Accuracy(c Classifier, data *mat64.Dense, labels []string) (float64, error) {
+	predicted, err := classify(c, data, labels)
+	if err != nil {
+		return 0.0, err
+	}
+	correct := 0
+	for i := range labels {
+		if predicted[i] == labels[i] {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(labels)), nil
+}
+
+// ConfusionMatrix returns confusion matrix of classifier c computed on the data samples stored
+// in rows of data matrix labeled with labels along with the class labels of its rows and columns.
+// Rows of confusion matrix correspond to the true classes and its columns to the predicted
+// classes: element (i, j) is the number of data samples of class i classified as class j.
+// Classes are ordered lexically and include both the true and the predicted classes.
+// It returns error if the number of labels does not match the number of data samples,
+// if any label is empty or if the data samples could not be classified.
+func ConfusionMatrix(c Classifier, data *mat64.Dense, labels []string) (*mat64.Dense, []string, error) {
+	predicted, err := classify(c, data, labels)
+	if err != nil {
+		return nil, nil, err
+	}
+	classes := uniqueLabels(labels, predicted)
+	index := make(map[string]int)
+	for i, class := range classes {
+		index[class] = i
+	}
+	confusion := mat64.NewDense(len(classes), len(classes), nil)
+	for i := range labels {
+		row, col := index[labels[i]], index[predicted[i]]
+		confusion.Set(row, col, confusion.At(row, col)+1)
+	}
+	return confusion, classes, nil
+}
+
+// classify classifies the data samples stored in rows of data matrix labeled with labels
+// using classifier c. It returns error if there are no labels, if the number of labels does
+// not match the number of data samples, if any label is empty or if the classifier returns
+// invalid number of labels.
+func classify(c Classifier, data *mat64.Dense, labels []string) ([]string, error) {
+	if err := validateLabels(data, labels); err != nil {
+		return nil, err
+	}
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("Incorrect number of labels: %d\n", len(labels))
+	}
+	predicted, err := c.Classify(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(predicted) != len(labels) {
+		return nil, fmt.Errorf("Incorrect number of predicted labels: %d\n", len(predicted))
+	}
+	return predicted, nil
+}
+
+// uniqueLabels returns lexically ordered distinct labels found in all supplied label slices
+func uniqueLabels(labelSets ...[]string) []string {
+	seen := make(map[string]bool)
+	unique := []string{}
+	for _, labels := range labelSets {
+		for _, label := range labels {
+			if !seen[label] {
+				seen[label] = true
+				unique = append(unique, label)
+			}
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
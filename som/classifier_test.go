@@ -0,0 +1,102 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+// constClassifier labels all data samples with the same label
+type constClassifier string
+
+func (c constClassifier) Classify(data *mat64.Dense) ([]string, error) {
+	rows, _ := data.Dims()
+	labels := make([]string, rows)
+	for i := range labels {
+		labels[i] = string(c)
+	}
+	return labels, nil
+}
+
+// badClassifier returns a single label regardless of the number of data samples
+type badClassifier struct{}
+
+func (c badClassifier) Classify(data *mat64.Dense) ([]string, error) {
+	return []string{"a"}, nil
+}
+
+func TestAccuracy(t *testing.T) {
+	assert := assert.New(t)
+
+	p, err := newLabeledPredictor(1, false)
+	assert.NoError(err)
+	data := mat64.NewDense(6, 1, []float64{0.1, 0.2, -0.1, 2.95, 3.1, 6.2})
+	labels := []string{"a", "a", "b", "b", "c", "c"}
+
+	testCases := []struct {
+		c        Classifier
+		accuracy float64
+	}{
+		{p, 4.0 / 6},
+		{constClassifier("a"), 2.0 / 6},
+		{constClassifier("d"), 0.0},
+	}
+	for _, tc := range testCases {
+		accuracy, err := Accuracy(tc.c, data, labels)
+		assert.NoError(err)
+		assert.InDelta(tc.accuracy, accuracy, 1e-9)
+	}
+
+	errCases := []struct {
+		c      Classifier
+		data   *mat64.Dense
+		labels []string
+	}{
+		{p, nil, labels},
+		{p, data, labels[1:]},
+		{p, mat64.NewDense(6, 2, nil), labels},
+		{p, data, []string{"a", "a", "b", "b", "", "c"}},
+		{badClassifier{}, data, labels},
+	}
+	for _, tc := range errCases {
+		accuracy, err := Accuracy(tc.c, tc.data, tc.labels)
+		assert.Equal(0.0, accuracy)
+		assert.Error(err)
+		confusion, classes, err := ConfusionMatrix(tc.c, tc.data, tc.labels)
+		assert.Nil(confusion)
+		assert.Nil(classes)
+		assert.Error(err)
+	}
+}
+
+func TestConfusionMatrix(t *testing.T) {
+	assert := assert.New(t)
+
+	p, err := newLabeledPredictor(1, false)
+	assert.NoError(err)
+	data := mat64.NewDense(6, 1, []float64{0.1, 0.2, -0.1, 2.95, 3.1, 6.2})
+	labels := []string{"a", "a", "b", "b", "c", "c"}
+
+	testCases := []struct {
+		c         Classifier
+		classes   []string
+		confusion []float64
+	}{
+		{p, []string{"a", "b", "c"}, []float64{
+			2, 0, 0,
+			1, 1, 0,
+			0, 1, 1}},
+		{constClassifier("d"), []string{"a", "b", "c", "d"}, []float64{
+			0, 0, 0, 2,
+			0, 0, 0, 2,
+			0, 0, 0, 2,
+			0, 0, 0, 0}},
+	}
+	for _, tc := range testCases {
+		confusion, classes, err := ConfusionMatrix(tc.c, data, labels)
+		assert.NoError(err)
+		assert.Equal(tc.classes, classes)
+		assert.Equal(tc.confusion, confusion.RawMatrix().Data)
+	}
+}
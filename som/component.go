@@ -0,0 +1,76 @@
+package som
+
+import (
+	"fmt"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// ComponentPlane contains values of a single codebook feature arranged on the map grid
+type ComponentPlane struct {
+	// Feature is the name of the codebook feature
+	Feature string
+	// Values contains codebook feature values: value of the unit in grid row r and column c
+	// is stored in row r and column c. Values of 1D maps are stored in a single row.
+	Values *mat64.Dense
+}
+
+// ComponentPlane returns values of the codebook feature with the given index arranged
+// on the map grid: see ComponentPlane.Values.
+// It returns error if the feature index is invalid or if the map is 3D.
+func (m Map) ComponentPlane(feature int) (*mat64.Dense, error) {
+	if _, cols := m.codebook.Dims(); feature < 0 || feature >= cols {
+		return nil, fmt.Errorf("Invalid feature index: %d\n", feature)
+	}
+	return m.gridValues(mat64.Col(nil, feature, m.codebook))
+}
+
+// ComponentPlanes returns component planes of all codebook features named by features.
+// If features is nil, the features are named x1, x2 etc. as in data sets. If unscale is not nil,
+// codebook vectors are transformed back to original data units before they are arranged
+// on the map grid: you can use it to undo the scaling of data the map was trained on.
+// It returns error if the number of feature names does not match the codebook dimension,
+// if the codebook could not be unscaled or if the map is 3D.
+func (m Map) ComponentPlanes(features []string, unscale UnscaleFunc) ([]ComponentPlane, error) {
+	_, cols := m.codebook.Dims()
+	if features != nil && len(features) != cols {
+		return nil, fmt.Errorf("Incorrect number of features supplied: %d\n", len(features))
+	}
+	codebook := m.codebook
+	if unscale != nil {
+		var err error
+		if codebook, err = unscale(m.codebook); err != nil {
+			return nil, err
+		}
+	}
+	planes := make([]ComponentPlane, cols)
+	for i := range planes {
+		values, err := m.gridValues(mat64.Col(nil, i, codebook))
+		if err != nil {
+			return nil, err
+		}
+		planes[i].Feature = fmt.Sprintf("x%d", i+1)
+		if features != nil {
+			planes[i].Feature = features[i]
+		}
+		planes[i].Values = values
+	}
+	return planes, nil
+}
+
+// gridValues arranges SOM unit values on the map grid.
+// It returns error if the map is 3D.
+func (m Map) gridValues(values []float64) (*mat64.Dense, error) {
+	dims := m.grid.Dims()
+	if len(dims) > 2 {
+		return nil, fmt.Errorf("Unsupported map dimensions: %d\n", len(dims))
+	}
+	rows := 1
+	if len(dims) == 2 {
+		rows = dims[0]
+	}
+	// units are stored in column-major order
+	grid := new(mat64.Dense)
+	grid.Clone(mat64.NewDense(len(values)/rows, rows, values).T())
+	return grid, nil
+}
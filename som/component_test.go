@@ -0,0 +1,99 @@
+package som
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponentPlane(t *testing.T) {
+	assert := assert.New(t)
+
+	// codebook vectors contain unit grid coordinates: x (column) and y (row)
+	coords, err := GridCoords("rectangle", []int{2, 3})
+	assert.NoError(err)
+	m, err := newUMatrixMap("planar", "rectangle", []int{2, 3}, coords)
+	assert.NoError(err)
+	xPlane, err := m.ComponentPlane(0)
+	assert.NoError(err)
+	assert.True(mat64.Equal(mat64.NewDense(2, 3, []float64{0, 1, 2, 0, 1, 2}), xPlane))
+	yPlane, err := m.ComponentPlane(1)
+	assert.NoError(err)
+	assert.True(mat64.Equal(mat64.NewDense(2, 3, []float64{0, 0, 0, 1, 1, 1}), yPlane))
+	// values are arranged in the same way as units in grid
+	for r := 0; r < 2; r++ {
+		for c := 0; c < 3; c++ {
+			unit, err := m.Grid().Index(r, c)
+			assert.NoError(err)
+			assert.Equal(m.Codebook().At(unit, 0), xPlane.At(r, c))
+			assert.Equal(m.Codebook().At(unit, 1), yPlane.At(r, c))
+		}
+	}
+	// 1D map
+	m1D, err := newUMatrixMap("planar", "rectangle", []int{4}, mat64.NewDense(4, 1, []float64{0, 1, 3, 6}))
+	assert.NoError(err)
+	plane, err := m1D.ComponentPlane(0)
+	assert.NoError(err)
+	assert.True(mat64.Equal(mat64.NewDense(1, 4, []float64{0, 1, 3, 6}), plane))
+
+	// invalid feature
+	for _, feature := range []int{-1, 2} {
+		plane, err = m.ComponentPlane(feature)
+		assert.Nil(plane)
+		assert.Error(err)
+	}
+	// 3D map
+	m3D, err := newUMatrixMap("planar", "rectangle", []int{2, 2, 2}, mat64.NewDense(8, 1, nil))
+	assert.NoError(err)
+	plane, err = m3D.ComponentPlane(0)
+	assert.Nil(plane)
+	assert.Error(err)
+}
+
+func TestComponentPlanes(t *testing.T) {
+	assert := assert.New(t)
+
+	coords, err := GridCoords("rectangle", []int{2, 3})
+	assert.NoError(err)
+	m, err := newUMatrixMap("planar", "rectangle", []int{2, 3}, coords)
+	assert.NoError(err)
+	// default feature names
+	planes, err := m.ComponentPlanes(nil, nil)
+	assert.NoError(err)
+	assert.Len(planes, 2)
+	for i, plane := range planes {
+		assert.Equal([]string{"x1", "x2"}[i], plane.Feature)
+		expected, err := m.ComponentPlane(i)
+		assert.NoError(err)
+		assert.True(mat64.Equal(expected, plane.Values))
+	}
+	// unscaled planes
+	unscale := func(mx *mat64.Dense) (*mat64.Dense, error) {
+		unscaled := new(mat64.Dense)
+		unscaled.Apply(func(i, j int, x float64) float64 {
+			return 10*x + 1
+		}, mx)
+		return unscaled, nil
+	}
+	planes, err = m.ComponentPlanes([]string{"x", "y"}, unscale)
+	assert.NoError(err)
+	assert.Equal("x", planes[0].Feature)
+	assert.Equal("y", planes[1].Feature)
+	assert.True(mat64.Equal(mat64.NewDense(2, 3, []float64{1, 11, 21, 1, 11, 21}), planes[0].Values))
+	assert.True(mat64.Equal(mat64.NewDense(2, 3, []float64{1, 1, 1, 11, 11, 11}), planes[1].Values))
+	// codebook is not modified
+	assert.True(mat64.Equal(coords, m.Codebook()))
+
+	// incorrect number of features
+	planes, err = m.ComponentPlanes([]string{"x"}, nil)
+	assert.Nil(planes)
+	assert.Error(err)
+	// unscaling error
+	planes, err = m.ComponentPlanes(nil, func(mx *mat64.Dense) (*mat64.Dense, error) {
+		return nil, errors.New("Test error")
+	})
+	assert.Nil(planes)
+	assert.Error(err)
+}
@@ -10,7 +10,9 @@ var UShape = map[string]bool{
 
 // CoordsInit maps supported grid coordinates function types to their implementations
 var CoordsInit = map[string]CoordsInitFunc{
-	"planar": GridCoords,
+	"planar":   GridCoords,
+	"toroid":   GridCoords,
+	"cylinder": GridCoords,
 }
 
 // Neighb maps supported neighbourhood functions to their implementations
@@ -20,41 +22,136 @@ var Neighb = map[string]NeighbFunc{
 	"mexican":  Mexican,
 }
 
-// Cool maps supported decay strategies
-var Cool = map[string]bool{
-	"lin": true,
-	"exp": true,
-	"inv": true,
+// Metric maps supported distance metrics to their implementations.
+// Minkowski distance of any order p is supported under the name minkowski:<p>,
+// e.g. minkowski:3, without being registered. You can register your own distance metric
+// by adding it to this map. Names euclidean and sqeuclidean are reserved and they must not be
+// overridden: BMU search computes their distances via matrix multiplication regardless of
+// the functions registered under them.
+var Metric = map[string]DistanceFunc{
+	"euclidean":   Euclidean,
+	"sqeuclidean": SqEuclidean,
+	"manhattan":   Manhattan,
+	"chebyshev":   Chebyshev,
+	"cosine":      Cosine,
+}
+
+// Lattice contains supported lattice grid distance metrics.
+// Lattice metrics count the number of steps between neighbouring SOM units needed to get
+// from one SOM unit to another instead of measuring the distance between their coordinates.
+// Hexagon units have 6 neighbours on both lattices.
+var Lattice = map[string]bool{
+	"lattice":           true, // rectangle units have 2 neighbours along each dimension
+	"lattice-chebyshev": true, // rectangle units have all adjacent units as neighbours
+}
+
+// Decay maps supported decay strategies to their implementations.
+// Decay strategies are used for both learning rate and radius.
+// You can register your own decay strategy by adding it to this map.
+var Decay = map[string]DecayFunc{
+	"lin":   LinDecay,
+	"exp":   ExpDecay,
+	"inv":   InvDecay,
+	"power": PowerDecay,
+	"step":  StepDecay,
 }
 
 // Config holds SOM configuration
 type Config struct {
-	// Dims specifies SOM dimensions
+	// Dims specifies SOM dimensions: 1D, 2D and 3D maps are supported
 	Dims []int
-	// Grid specifies the type of SOM grid: planar
+	// Grid specifies the type of SOM grid: planar, toroid, cylinder
 	Grid string
+	// Axis specifies index of the map dimension in Dims a cylinder grid wraps around
+	Axis int
 	// InitFunc specifies codebook initialization function
 	InitFunc CodebookInitFunc
 	// UShape specifies SOM unit shape: hexagon, rectangle
 	UShape string
 	// Radius specifies initial SOM units radius
-	Radius int
-	// RDecay specifies radius decay strategy: lin, exp
+	Radius float64
+	// RadiusFinal specifies SOM units radius at the end of training
+	RadiusFinal float64
+	// RDecay specifies radius decay strategy: lin, exp, inv, power, step
 	RDecay string
 	// NeighbFn specifies SOM neighbourhood function: gaussian, bubble, mexican
 	NeighbFn string
 	// LRate specifies initial SOM learning rate
-	LRate int
-	// LDecay specifies learning rate decay strategy: lin, exp
+	LRate float64
+	// LRateFinal specifies SOM learning rate at the end of training
+	LRateFinal float64
+	// LDecay specifies learning rate decay strategy: lin, exp, inv, power, step
 	LDecay string
+	// Metric specifies distance metric used in BMU search: euclidean by default
+	Metric string
+	// GridMetric specifies distance metric used to compute distances between SOM units:
+	// any of the Metric or Lattice metrics, euclidean by default
+	GridMetric string
+	// GridCache specifies number of rows of grid distances cached during training:
+	// 0 disables caching
+	GridCache int
+	// Workers specifies number of goroutines used in batch training: 0 means 1
+	Workers int
+	// Layers specifies codebook layers of supervised SOMs: see Layer.
+	// If no layers are specified, all data features form a single layer
+	Layers []Layer
 }
 
 // validateConfig validates SOM configuration.
 // It returns error if any of the config parameters are invalid
 func validateConfig(c *Config) error {
-	// SOM must have 2 dimensions
-	// TODO: figure out 3D maps
-	if dimLen := len(c.Dims); dimLen != 2 {
+	// validate SOM grid configuration
+	if err := validateGridConfig(c); err != nil {
+		return err
+	}
+	// initial SOM unit radius must be greater than zero
+	if c.Radius < 0 {
+		return fmt.Errorf("Invalid SOM unit radius: %f\n", c.Radius)
+	}
+	// final SOM unit radius can't be negative or bigger than initial radius
+	if c.RadiusFinal < 0 || (c.Radius > 0 && c.RadiusFinal > c.Radius) {
+		return fmt.Errorf("Invalid SOM unit final radius: %f\n", c.RadiusFinal)
+	}
+	// check Radius decay strategy
+	if _, ok := Decay[c.RDecay]; !ok {
+		return fmt.Errorf("Unsupported Radius decay strategy: %s\n", c.RDecay)
+	}
+	// hcheck the supplied neighbourhood function
+	if _, ok := Neighb[c.NeighbFn]; !ok {
+		return fmt.Errorf("Unsupported Neighbourhood function: %s\n", c.NeighbFn)
+	}
+	// initial SOM learning rate must be greater than zero
+	if c.LRate < 0 {
+		return fmt.Errorf("Invalid SOM learning rate: %f\n", c.LRate)
+	}
+	// final SOM learning rate can't be negative or bigger than initial learning rate
+	if c.LRateFinal < 0 || (c.LRate > 0 && c.LRateFinal > c.LRate) {
+		return fmt.Errorf("Invalid SOM final learning rate: %f\n", c.LRateFinal)
+	}
+	// check Learning rate decay strategy
+	if _, ok := Decay[c.LDecay]; !ok {
+		return fmt.Errorf("Unsupported Learning rate decay strategy: %s\n", c.LDecay)
+	}
+	// check the supplied distance metric
+	if _, ok := metricFunc(c.Metric); c.Metric != "" && !ok {
+		return fmt.Errorf("Unsupported distance metric: %s\n", c.Metric)
+	}
+	// number of batch training workers can't be negative
+	if c.Workers < 0 {
+		return fmt.Errorf("Invalid number of workers: %d\n", c.Workers)
+	}
+	// validate codebook layers
+	if err := validateLayers(c.Layers); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateGridConfig validates SOM grid configuration.
+// It returns error if any of the grid config parameters are invalid
+func validateGridConfig(c *Config) error {
+	// SOM must have 1, 2 or 3 dimensions
+	if dimLen := len(c.Dims); dimLen < 1 || dimLen > 3 {
 		return fmt.Errorf("Incorrect number of dimensions supplied: %d\n", dimLen)
 	}
 	// check if the supplied dimensions are negative integers
@@ -71,25 +168,26 @@ func validateConfig(c *Config) error {
 	if _, ok := UShape[c.UShape]; !ok {
 		return fmt.Errorf("Unsupported SOM unit shape: %s\n", c.UShape)
 	}
-	// initial SOM unit radius must be greater than zero
-	if c.Radius < 0 {
-		return fmt.Errorf("Invalid SOM unit radius: %d\n", c.Radius)
+	// hexagon units can't be used in 3D maps
+	if c.UShape == "hexagon" && len(c.Dims) > 2 {
+		return fmt.Errorf("Unsupported hexagon map dimensions: %d\n", len(c.Dims))
 	}
-	// check Radius decay strategy
-	if _, ok := Cool[c.RDecay]; !ok {
-		return fmt.Errorf("Unsupported Radius decay strategy: %s\n", c.RDecay)
+	// cylinder can only wrap around one of the map dimensions
+	if c.Grid == "cylinder" && (c.Axis < 0 || c.Axis >= len(c.Dims)) {
+		return fmt.Errorf("Invalid cylinder axis: %d\n", c.Axis)
 	}
-	// hcheck the supplied neighbourhood function
-	if _, ok := Neighb[c.NeighbFn]; !ok {
-		return fmt.Errorf("Unsupported Neighbourhood function: %s\n", c.NeighbFn)
+	// hexagon grid can only wrap around even number of rows
+	wrapRows := c.Grid == "toroid" || (c.Grid == "cylinder" && c.Axis == 0)
+	if wrapRows && c.UShape == "hexagon" && len(c.Dims) == 2 && c.Dims[0]%2 != 0 {
+		return fmt.Errorf("Hexagon %s requires even number of rows: %d\n", c.Grid, c.Dims[0])
 	}
-	// initial SOM learning rate must be greater than zero
-	if c.LRate < 0 {
-		return fmt.Errorf("Invalid SOM learning rate: %d\n", c.LRate)
+	// check the supplied grid distance metric
+	if _, ok := metricFunc(c.GridMetric); c.GridMetric != "" && !ok && !Lattice[c.GridMetric] {
+		return fmt.Errorf("Unsupported grid distance metric: %s\n", c.GridMetric)
 	}
-	// check Learning rate decay strategy
-	if _, ok := Cool[c.LDecay]; !ok {
-		return fmt.Errorf("Unsupported Learning rate decay strategy: %s\n", c.LDecay)
+	// number of cached grid distance rows can't be negative
+	if c.GridCache < 0 {
+		return fmt.Errorf("Invalid grid cache size: %d\n", c.GridCache)
 	}
 	return nil
 }
@@ -32,9 +32,10 @@ func TestValidateDims(t *testing.T) {
 		expErr bool
 		errStr string
 	}{
-		{[]int{1}, true, fmt.Sprintf(errDimLen, 1)},
+		{[]int{1}, false, ""},
 		{[]int{}, true, fmt.Sprintf(errDimLen, 0)},
 		{[]int{1, 2}, false, ""},
+		{[]int{1, 2, 3, 4}, true, fmt.Sprintf(errDimLen, 4)},
 		{wrongDims, true, fmt.Sprintf(errDimVal, wrongDims)},
 	}
 
@@ -60,6 +61,8 @@ func TestValidateGrid(t *testing.T) {
 		expErr bool
 	}{
 		{"planar", false},
+		{"toroid", false},
+		{"cylinder", false},
 		{"foobar", true},
 	}
 
@@ -105,9 +108,9 @@ func TestValidateUshape(t *testing.T) {
 func TestValidateRadius(t *testing.T) {
 	assert := assert.New(t)
 
-	errString := "Invalid SOM unit radius: %d\n"
+	errString := "Invalid SOM unit radius: %f\n"
 	testCases := []struct {
-		radius int
+		radius float64
 		expErr bool
 	}{
 		{1, false},
@@ -128,6 +131,35 @@ func TestValidateRadius(t *testing.T) {
 	c.Radius = origRadius
 }
 
+func TestValidateRadiusFinal(t *testing.T) {
+	assert := assert.New(t)
+
+	errString := "Invalid SOM unit final radius: %f\n"
+	testCases := []struct {
+		radius float64
+		final  float64
+		expErr bool
+	}{
+		{2, 1, false},
+		{2, 0, false},
+		{0, 1, false},
+		{2, -1, true},
+		{2, 3, true},
+	}
+
+	origRadius, origFinal := c.Radius, c.RadiusFinal
+	for _, tc := range testCases {
+		c.Radius, c.RadiusFinal = tc.radius, tc.final
+		err := validateConfig(c)
+		if tc.expErr {
+			assert.EqualError(err, fmt.Sprintf(errString, c.RadiusFinal))
+		} else {
+			assert.NoError(err)
+		}
+	}
+	c.Radius, c.RadiusFinal = origRadius, origFinal
+}
+
 func TestValidateRDecay(t *testing.T) {
 	assert := assert.New(t)
 
@@ -140,6 +172,8 @@ func TestValidateRDecay(t *testing.T) {
 		{"foobar", true},
 		{"exp", false},
 		{"inv", false},
+		{"power", false},
+		{"step", false},
 	}
 
 	origRDecay := c.RDecay
@@ -185,12 +219,13 @@ func TestValidateNeighbFn(t *testing.T) {
 func TestValidateLRate(t *testing.T) {
 	assert := assert.New(t)
 
-	errString := "Invalid SOM learning rate: %d\n"
+	errString := "Invalid SOM learning rate: %f\n"
 	testCases := []struct {
-		lrate  int
+		lrate  float64
 		expErr bool
 	}{
 		{1, false},
+		{0.5, false},
 		{-10, true},
 		{0, false},
 	}
@@ -208,6 +243,35 @@ func TestValidateLRate(t *testing.T) {
 	c.LRate = origLRate
 }
 
+func TestValidateLRateFinal(t *testing.T) {
+	assert := assert.New(t)
+
+	errString := "Invalid SOM final learning rate: %f\n"
+	testCases := []struct {
+		lrate  float64
+		final  float64
+		expErr bool
+	}{
+		{0.5, 0.01, false},
+		{0.5, 0, false},
+		{0, 0.1, false},
+		{0.5, -0.1, true},
+		{0.5, 0.7, true},
+	}
+
+	origLRate, origFinal := c.LRate, c.LRateFinal
+	for _, tc := range testCases {
+		c.LRate, c.LRateFinal = tc.lrate, tc.final
+		err := validateConfig(c)
+		if tc.expErr {
+			assert.EqualError(err, fmt.Sprintf(errString, c.LRateFinal))
+		} else {
+			assert.NoError(err)
+		}
+	}
+	c.LRate, c.LRateFinal = origLRate, origFinal
+}
+
 func TestValidateLDecay(t *testing.T) {
 	assert := assert.New(t)
 
@@ -220,6 +284,8 @@ func TestValidateLDecay(t *testing.T) {
 		{"exp", false},
 		{"foobar", true},
 		{"inv", false},
+		{"power", false},
+		{"step", false},
 	}
 
 	origLDecay := c.LDecay
@@ -234,3 +300,205 @@ func TestValidateLDecay(t *testing.T) {
 	}
 	c.LDecay = origLDecay
 }
+
+func TestValidateWorkers(t *testing.T) {
+	assert := assert.New(t)
+
+	errString := "Invalid number of workers: %d\n"
+	testCases := []struct {
+		workers int
+		expErr  bool
+	}{
+		{0, false},
+		{4, false},
+		{-1, true},
+	}
+
+	origWorkers := c.Workers
+	for _, tc := range testCases {
+		c.Workers = tc.workers
+		err := validateConfig(c)
+		if tc.expErr {
+			assert.EqualError(err, fmt.Sprintf(errString, c.Workers))
+		} else {
+			assert.NoError(err)
+		}
+	}
+	c.Workers = origWorkers
+}
+
+func TestValidateMetric(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		metric     string
+		gridMetric string
+		expErr     bool
+		errStr     string
+	}{
+		{"", "", false, ""},
+		{"cosine", "manhattan", false, ""},
+		{"", "lattice", false, ""},
+		{"", "lattice-chebyshev", false, ""},
+		{"minkowski:3", "minkowski:1.5", false, ""},
+		{"foobar", "", true, "Unsupported distance metric: foobar\n"},
+		{"minkowski", "", true, "Unsupported distance metric: minkowski\n"},
+		{"minkowski:0.5", "", true, "Unsupported distance metric: minkowski:0.5\n"},
+		{"", "minkowski:x", true, "Unsupported grid distance metric: minkowski:x\n"},
+		{"", "foobar", true, "Unsupported grid distance metric: foobar\n"},
+	}
+
+	origMetric, origGridMetric := c.Metric, c.GridMetric
+	for _, tc := range testCases {
+		c.Metric, c.GridMetric = tc.metric, tc.gridMetric
+		err := validateConfig(c)
+		if tc.expErr {
+			assert.EqualError(err, tc.errStr)
+		} else {
+			assert.NoError(err)
+		}
+	}
+	c.Metric, c.GridMetric = origMetric, origGridMetric
+}
+
+func TestValidateToroid(t *testing.T) {
+	assert := assert.New(t)
+
+	errString := "Hexagon toroid requires even number of rows: %d\n"
+	testCases := []struct {
+		uShape string
+		dims   []int
+		expErr bool
+	}{
+		{"hexagon", []int{4, 3}, false},
+		{"hexagon", []int{3, 4}, true},
+		{"rectangle", []int{3, 4}, false},
+	}
+
+	origGrid, origUShape, origDims := c.Grid, c.UShape, c.Dims
+	c.Grid = "toroid"
+	for _, tc := range testCases {
+		c.UShape, c.Dims = tc.uShape, tc.dims
+		err := validateConfig(c)
+		if tc.expErr {
+			assert.EqualError(err, fmt.Sprintf(errString, c.Dims[0]))
+		} else {
+			assert.NoError(err)
+		}
+	}
+	c.Grid, c.UShape, c.Dims = origGrid, origUShape, origDims
+}
+
+func TestValidateCylinder(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		uShape string
+		dims   []int
+		axis   int
+		expErr bool
+		errStr string
+	}{
+		{"hexagon", []int{4, 3}, 0, false, ""},
+		{"hexagon", []int{3, 4}, 1, false, ""},
+		{"rectangle", []int{3, 4}, 0, false, ""},
+		{"hexagon", []int{3, 4}, 0, true, "Hexagon cylinder requires even number of rows: 3\n"},
+		{"rectangle", []int{3, 4}, 2, true, "Invalid cylinder axis: 2\n"},
+		{"rectangle", []int{3, 4}, -1, true, "Invalid cylinder axis: -1\n"},
+	}
+
+	origGrid, origUShape, origDims, origAxis := c.Grid, c.UShape, c.Dims, c.Axis
+	c.Grid = "cylinder"
+	for _, tc := range testCases {
+		c.UShape, c.Dims, c.Axis = tc.uShape, tc.dims, tc.axis
+		err := validateConfig(c)
+		if tc.expErr {
+			assert.EqualError(err, tc.errStr)
+		} else {
+			assert.NoError(err)
+		}
+	}
+	c.Grid, c.UShape, c.Dims, c.Axis = origGrid, origUShape, origDims, origAxis
+}
+
+func TestValidateHexagonDims(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		uShape string
+		dims   []int
+		expErr bool
+	}{
+		{"hexagon", []int{4}, false},
+		{"hexagon", []int{4, 3}, false},
+		{"hexagon", []int{4, 3, 2}, true},
+		{"rectangle", []int{4, 3, 2}, false},
+	}
+
+	origUShape, origDims := c.UShape, c.Dims
+	for _, tc := range testCases {
+		c.UShape, c.Dims = tc.uShape, tc.dims
+		err := validateConfig(c)
+		if tc.expErr {
+			assert.EqualError(err, fmt.Sprintf("Unsupported hexagon map dimensions: %d\n", len(c.Dims)))
+		} else {
+			assert.NoError(err)
+		}
+	}
+	c.UShape, c.Dims = origUShape, origDims
+}
+
+func TestValidateGridCache(t *testing.T) {
+	assert := assert.New(t)
+
+	errString := "Invalid grid cache size: %d\n"
+	testCases := []struct {
+		gridCache int
+		expErr    bool
+	}{
+		{0, false},
+		{100, false},
+		{-1, true},
+	}
+
+	origGridCache := c.GridCache
+	for _, tc := range testCases {
+		c.GridCache = tc.gridCache
+		err := validateConfig(c)
+		if tc.expErr {
+			assert.EqualError(err, fmt.Sprintf(errString, c.GridCache))
+		} else {
+			assert.NoError(err)
+		}
+	}
+	c.GridCache = origGridCache
+}
+
+func TestValidateLayers(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		layers []Layer
+		errStr string
+	}{
+		{nil, ""},
+		{[]Layer{{Dim: 2, Weight: 0.5}, {Dim: 1, Weight: 0.5, Metric: "manhattan"}}, ""},
+		{[]Layer{{Dim: 2, Weight: 1}, {Dim: 1, Weight: 0}}, ""},
+		{[]Layer{{Dim: 0, Weight: 1}}, "Invalid layer dimension: 0\n"},
+		{[]Layer{{Dim: 2, Weight: -1}}, fmt.Sprintf("Invalid layer weight: %f\n", -1.0)},
+		{[]Layer{{Dim: 2, Weight: 1, Metric: "foo"}}, "Unsupported layer distance metric: foo\n"},
+		{[]Layer{{Dim: 2}, {Dim: 1}}, fmt.Sprintf("Invalid layer weights: %v\n", []Layer{{Dim: 2}, {Dim: 1}})},
+	}
+
+	origLayers := c.Layers
+	for _, tc := range testCases {
+		c.Layers = tc.layers
+		err := validateConfig(c)
+		if tc.errStr != "" {
+			assert.EqualError(err, tc.errStr)
+		} else {
+			assert.NoError(err)
+		}
+	}
+	c.Layers = origLayers
+}
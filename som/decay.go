@@ -0,0 +1,58 @@
+package som
+
+import "math"
+
+// decaySteps is the number of equal steps StepDecay decays the value in
+const decaySteps = 10
+
+// LinDecay decays init value linearly towards final value.
+// It returns the value at step out of steps steps.
+func LinDecay(init, final float64, step, steps int) float64 {
+	return init + (final-init)*progress(step, steps)
+}
+
+// ExpDecay decays init value exponentially towards final value.
+// It returns the value at step out of steps steps.
+// If either of the values is not positive, it falls back to LinDecay.
+func ExpDecay(init, final float64, step, steps int) float64 {
+	if init <= 0 || final <= 0 {
+		return LinDecay(init, final, step, steps)
+	}
+	return init * math.Pow(final/init, progress(step, steps))
+}
+
+// InvDecay decays init value inversely proportionally to the step towards final value.
+// It returns the value at step out of steps steps.
+// If either of the values is not positive, it falls back to LinDecay.
+func InvDecay(init, final float64, step, steps int) float64 {
+	if init <= 0 || final <= 0 {
+		return LinDecay(init, final, step, steps)
+	}
+	return init / (1 + (init/final-1)*progress(step, steps))
+}
+
+// PowerDecay decays init value following power law of the step towards final value.
+// It returns the value at step out of steps steps.
+// If either of the values is not positive, it falls back to LinDecay.
+func PowerDecay(init, final float64, step, steps int) float64 {
+	if init <= 0 || final <= 0 || steps <= 0 {
+		return LinDecay(init, final, step, steps)
+	}
+	alpha := math.Log(init/final) / math.Log(float64(steps)+1)
+	return init * math.Pow(float64(step)+1, -alpha)
+}
+
+// StepDecay decays init value towards final value in decaySteps equal steps.
+// It returns the value at step out of steps steps.
+func StepDecay(init, final float64, step, steps int) float64 {
+	stair := math.Floor(progress(step, steps) * decaySteps)
+	return init + (final-init)*stair/decaySteps
+}
+
+// progress returns the fraction of steps done at step
+func progress(step, steps int) float64 {
+	if steps <= 0 {
+		return 1.0
+	}
+	return float64(step) / float64(steps)
+}
@@ -0,0 +1,54 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecay(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		decayFn  DecayFunc
+		step     int
+		expected float64
+	}{
+		{LinDecay, 0, 10.0},
+		{LinDecay, 5, 5.05},
+		{LinDecay, 10, 0.1},
+		{ExpDecay, 0, 10.0},
+		{ExpDecay, 5, 1.0},
+		{ExpDecay, 10, 0.1},
+		{InvDecay, 0, 10.0},
+		{InvDecay, 5, 0.198},
+		{InvDecay, 10, 0.1},
+		{PowerDecay, 0, 10.0},
+		{PowerDecay, 10, 0.1},
+		{StepDecay, 0, 10.0},
+		{StepDecay, 1, 9.01},
+		{StepDecay, 5, 5.05},
+		{StepDecay, 10, 0.1},
+	}
+
+	for _, tc := range testCases {
+		assert.InDelta(tc.expected, tc.decayFn(10.0, 0.1, tc.step, 10), 0.001)
+	}
+
+	// all decay strategies decrease values monotonically
+	for name, decayFn := range Decay {
+		prev := decayFn(2.0, 0.5, 0, 100)
+		for step := 1; step <= 100; step++ {
+			val := decayFn(2.0, 0.5, step, 100)
+			assert.True(val <= prev, name)
+			prev = val
+		}
+	}
+
+	// non-positive final values fall back to linear decay
+	for _, decayFn := range []DecayFunc{ExpDecay, InvDecay, PowerDecay} {
+		assert.InDelta(5.0, decayFn(10.0, 0.0, 5, 10), 0.001)
+	}
+	// zero steps
+	assert.InDelta(0.1, LinDecay(10.0, 0.1, 0, 0), 0.001)
+}
@@ -3,78 +3,154 @@ package som
 import (
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 
 	"github.com/gonum/matrix/mat64"
 )
 
-// Distance calculates a distance metric between vectors a and b.
-// If unsupported metric is requested DistVector returns euclidean ddistance.
-// It returns error if the supplied vectors are either nil are different dimension
+// minkowskiPrefix is the prefix of the names of Minkowski distance metrics: see metricFunc
+const minkowskiPrefix = "minkowski:"
+
+// Distance calculates a distance between vectors a and b using the requested metric.
+// Supported metrics are registered in Metric map or they are Minkowski distances named
+// minkowski:<p> where p is the distance order e.g. minkowski:3.
+// It returns error if the metric is not supported or if the supplied vectors are either nil
+// or have different dimensions.
 func Distance(metric string, a, b *mat64.Vector) (float64, error) {
-	switch metric {
-	case "euclidean":
-		return euclideanVec(a, b)
-	default:
-		return euclideanVec(a, b)
+	distFn, ok := metricFunc(metric)
+	if !ok {
+		return 0.0, fmt.Errorf("Unsupported distance metric: %s\n", metric)
 	}
+	return distFn(a, b)
 }
 
-// DistanceMx calculates a distance matrix for the given matrix using the given method.
+// DistanceMx calculates a distance matrix for the given matrix using the given metric.
 // It returns a hollow symmetric matrix where an item x_ij contains the distance between
-// vectors stored in rows i and j. You can request different distance metrics via metric
-// parameter. If an unknown metric is supplied Euclidean distance is computed.
+// vectors stored in rows i and j. Supported metrics are the same as in Distance.
+// It returns error if the metric is not supported or if the supplied matrix is nil.
 func DistanceMx(metric string, matrix *mat64.Dense) (*mat64.Dense, error) {
-	switch metric {
-	case "euclidean":
-		return euclideanMx(matrix)
-	default:
-		return euclideanMx(matrix)
+	distFn, ok := metricFunc(metric)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported distance metric: %s\n", metric)
+	}
+	if matrix == nil {
+		return nil, fmt.Errorf("Invalid matrix supplied: %v\n", matrix)
+	}
+	rows, _ := matrix.Dims()
+	out := mat64.NewDense(rows, rows, nil)
+	for row := 0; row < rows-1; row++ {
+		a := matrix.RowView(row)
+		for i := row + 1; i < rows; i++ {
+			dist, err := distFn(a, matrix.RowView(i))
+			if err != nil {
+				return nil, err
+			}
+			out.Set(row, i, dist)
+			out.Set(i, row, dist)
+		}
 	}
+	return out, nil
 }
 
-// euclideanVec computes euclidean distances between vectors a and b.
-// It returns error if the supplied vectors are either nil are different dimension
-func euclideanVec(a, b *mat64.Vector) (float64, error) {
-	if a == nil || b == nil {
-		return 0.0, fmt.Errorf("Invalid vectors supplied. a: %v, b: %v\n", a, b)
-	}
-	if a.Len() != b.Len() {
-		return 0.0, fmt.Errorf("Incorrect vector dims. a: %d, b: %d\n", a.Len(), b.Len())
+// Euclidean computes Euclidean distance between vectors a and b.
+// It returns error if the supplied vectors are either nil or have different dimensions.
+func Euclidean(a, b *mat64.Vector) (float64, error) {
+	dist, err := SqEuclidean(a, b)
+	if err != nil {
+		return 0.0, err
 	}
+	return math.Sqrt(dist), nil
+}
 
-	d := new(mat64.Dense)
-	d.Scale(-1.0, b)
-	d.Add(a, d)
+// SqEuclidean computes squared Euclidean distance between vectors a and b.
+// It returns error if the supplied vectors are either nil or have different dimensions.
+func SqEuclidean(a, b *mat64.Vector) (float64, error) {
+	return withValidVecs(a, b, func(dist, x, y float64) float64 {
+		return dist + (x-y)*(x-y)
+	})
+}
 
-	// power function to be applied to each matrix element
-	powFn := func(i, j int, x float64) float64 {
-		return math.Pow(x, 2.0)
-	}
-	d.Apply(powFn, d)
+// Manhattan computes Manhattan (city block) distance between vectors a and b.
+// It returns error if the supplied vectors are either nil or have different dimensions.
+func Manhattan(a, b *mat64.Vector) (float64, error) {
+	return withValidVecs(a, b, func(dist, x, y float64) float64 {
+		return dist + math.Abs(x-y)
+	})
+}
 
-	return math.Sqrt(mat64.Sum(d)), nil
+// Chebyshev computes Chebyshev (maximum coordinate difference) distance between vectors a and b.
+// It returns error if the supplied vectors are either nil or have different dimensions.
+func Chebyshev(a, b *mat64.Vector) (float64, error) {
+	return withValidVecs(a, b, func(dist, x, y float64) float64 {
+		return math.Max(dist, math.Abs(x-y))
+	})
 }
 
-func euclideanMx(in *mat64.Dense) (*mat64.Dense, error) {
-	if in == nil {
-		return nil, fmt.Errorf("Invalid matrix supplied: %v\n", in)
+// metricFunc returns distance function of the requested metric. Metric names of the form
+// minkowski:<p> denote Minkowski distance of order p, which must be at least 1.
+// It returns false if the metric is not supported.
+func metricFunc(metric string) (DistanceFunc, bool) {
+	if distFn, ok := Metric[metric]; ok {
+		return distFn, true
 	}
+	if !strings.HasPrefix(metric, minkowskiPrefix) {
+		return nil, false
+	}
+	p, err := strconv.ParseFloat(strings.TrimPrefix(metric, minkowskiPrefix), 64)
+	if err != nil || p < 1 || math.IsInf(p, 1) {
+		return nil, false
+	}
+	return Minkowski(p), true
+}
 
-	rows, _ := in.Dims()
-	out := mat64.NewDense(rows, rows, nil)
-
-	for row := 0; row < rows-1; row++ {
-		dist := 0.0
-		a := in.RowView(row)
-		for i := row + 1; i < rows; i++ {
-			if i != row {
-				b := in.RowView(i)
-				dist, _ = euclideanVec(a, b)
-				out.Set(row, i, dist)
-				out.Set(i, row, dist)
-			}
+// Minkowski returns a function which computes Minkowski distance of order p between vectors.
+// The returned function returns error if the supplied vectors are either nil or have
+// different dimensions or if p is smaller than 1.
+func Minkowski(p float64) DistanceFunc {
+	return func(a, b *mat64.Vector) (float64, error) {
+		if p < 1 {
+			return 0.0, fmt.Errorf("Invalid Minkowski distance order: %f\n", p)
+		}
+		dist, err := withValidVecs(a, b, func(dist, x, y float64) float64 {
+			return dist + math.Pow(math.Abs(x-y), p)
+		})
+		if err != nil {
+			return 0.0, err
 		}
+		return math.Pow(dist, 1/p), nil
 	}
+}
 
-	return out, nil
+// Cosine computes cosine distance between vectors a and b i.e. 1 - cosine similarity.
+// If either of the vectors is a zero vector, their distance is 1.
+// It returns error if the supplied vectors are either nil or have different dimensions.
+func Cosine(a, b *mat64.Vector) (float64, error) {
+	dot, err := withValidVecs(a, b, func(dot, x, y float64) float64 {
+		return dot + x*y
+	})
+	if err != nil {
+		return 0.0, err
+	}
+	normA, normB := mat64.Norm(a, 2), mat64.Norm(b, 2)
+	if normA == 0 || normB == 0 {
+		return 1.0, nil
+	}
+	return 1 - dot/(normA*normB), nil
+}
+
+// withValidVecs folds function fn over the elements of vectors a and b and returns the result.
+// It returns error if the supplied vectors are either nil or have different dimensions.
+func withValidVecs(a, b *mat64.Vector, fn func(acc, x, y float64) float64) (float64, error) {
+	if a == nil || b == nil {
+		return 0.0, fmt.Errorf("Invalid vectors supplied. a: %v, b: %v\n", a, b)
+	}
+	if a.Len() != b.Len() {
+		return 0.0, fmt.Errorf("Incorrect vector dims. a: %d, b: %d\n", a.Len(), b.Len())
+	}
+	acc := 0.0
+	for i := 0; i < a.Len(); i++ {
+		acc = fn(acc, a.At(i, 0), b.At(i, 0))
+	}
+	return acc, nil
 }
@@ -28,16 +28,75 @@ func TestDistance(t *testing.T) {
 		assert.InDelta(tc.expected, dist, 0.01)
 	}
 
+	// unsupported metric
+	a := mat64.NewVector(2, []float64{0.0, 0.0})
+	d, err := Distance("foobar", a, a)
+	assert.Error(err)
+	assert.Equal(0.0, d)
 	// nil vectors
-	d, err := Distance("euclidean", nil, nil)
+	d, err = Distance("euclidean", nil, nil)
 	assert.Error(err)
 	assert.Equal(0.0, d)
 	// different vector dimensions
-	a := mat64.NewVector(2, []float64{0.0, 0.0})
 	b := mat64.NewVector(1, []float64{1.0})
-	d, err = Distance("euclidean", a, b)
+	for metric := range Metric {
+		d, err = Distance(metric, a, b)
+		assert.Error(err)
+		assert.Equal(0.0, d)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	assert := assert.New(t)
+
+	a := mat64.NewVector(3, []float64{1.0, 2.0, 3.0})
+	b := mat64.NewVector(3, []float64{4.0, 0.0, 3.0})
+	zero := mat64.NewVector(3, []float64{0.0, 0.0, 0.0})
+	testCases := []struct {
+		metric   string
+		a        *mat64.Vector
+		b        *mat64.Vector
+		expected float64
+	}{
+		{"euclidean", a, b, 3.6056},
+		{"sqeuclidean", a, b, 13.0},
+		{"manhattan", a, b, 5.0},
+		{"chebyshev", a, b, 3.0},
+		{"minkowski:3", a, b, 3.2711},
+		{"minkowski:1", a, b, 5.0},
+		{"minkowski:4", a, b, 3.1383},
+		{"cosine", a, b, 0.3051},
+		{"cosine", a, a, 0.0},
+		{"cosine", a, zero, 1.0},
+	}
+
+	for _, tc := range testCases {
+		dist, err := Distance(tc.metric, tc.a, tc.b)
+		assert.NoError(err)
+		assert.InDelta(tc.expected, dist, 0.001, tc.metric)
+		// all metrics are symmetric
+		revDist, err := Distance(tc.metric, tc.b, tc.a)
+		assert.NoError(err)
+		assert.InDelta(dist, revDist, 1e-12, tc.metric)
+	}
+
+	// Minkowski distance of order 1 and 2
+	dist, err := Minkowski(1)(a, b)
+	assert.NoError(err)
+	assert.InDelta(5.0, dist, 0.001)
+	dist, err = Minkowski(2)(a, b)
+	assert.NoError(err)
+	assert.InDelta(3.6056, dist, 0.001)
+	// invalid Minkowski order
+	dist, err = Minkowski(0.5)(a, b)
 	assert.Error(err)
-	assert.Equal(0.0, d)
+	assert.Equal(0.0, dist)
+	// invalid Minkowski metric names
+	for _, metric := range []string{"minkowski", "minkowski:", "minkowski:0.5", "minkowski:x", "minkowski:+Inf"} {
+		dist, err = Distance(metric, a, b)
+		assert.Error(err, metric)
+		assert.Equal(0.0, dist)
+	}
 }
 
 func TestDistanceMx(t *testing.T) {
@@ -95,4 +154,19 @@ func TestDistanceMx(t *testing.T) {
 
 	assert.Error(err)
 	assert.Nil(nilMatrix)
+
+	manhattanOutExpected := mat64.NewDense(negativeR, negativeR, []float64{
+		0.0, 100.0,
+		100.0, 0.0,
+	})
+
+	manhattanOut, err := DistanceMx("manhattan", negative)
+
+	assert.NoError(err)
+	assert.True(mat64.EqualApprox(manhattanOutExpected, manhattanOut, 0.01))
+
+	unsupported, err := DistanceMx("foobar", one)
+
+	assert.Error(err)
+	assert.Nil(unsupported)
 }
@@ -34,6 +34,11 @@ func GridDims(data *mat64.Dense, uShape string) ([]int, error) {
 		gDim := math.Sqrt(mUnits)
 		return []int{int(gDim), int(gDim)}, nil
 	}
+	// missing values are replaced with feature means
+	data, err := fillMissing(data)
+	if err != nil {
+		return nil, err
+	}
 	// We have more than 2 samples and more than 1D data
 	// Calculate eigenvalue ie. SVD singular values
 	// eigVals returned here are actually their square values -
@@ -63,6 +68,61 @@ func GridDims(data *mat64.Dense, uShape string) ([]int, error) {
 	return []int{xDim, yDim}, nil
 }
 
+// GridDimsN tries to estimate the best dimensions of map with mapDim dimensions from data
+// matrix and given unit shape. 2D map dimensions are estimated using GridDims. 3D map
+// dimensions are calculated from the ratios of three highest input eigenvalues and
+// 1D maps are simple chains of units.
+// It returns error if the map dimensions could not be calculated, if unsupported number
+// of map dimensions is requested or if 3D map of hexagon units is requested.
+func GridDimsN(data *mat64.Dense, uShape string, mapDim int) ([]int, error) {
+	// data matrix can't be nil
+	if data == nil {
+		return nil, fmt.Errorf("Invalid data matrix: %v\n", data)
+	}
+	switch mapDim {
+	case 2:
+		return GridDims(data, uShape)
+	case 1, 3:
+	default:
+		return nil, fmt.Errorf("Unsupported dimensions requested: %d\n", mapDim)
+	}
+	dataLen, dataDim := data.Dims()
+	// this is a simple heuristic - you can pick the scale > 5
+	mUnits := math.Ceil(5 * math.Sqrt(float64(dataLen)))
+	if mapDim == 1 {
+		return []int{int(mUnits)}, nil
+	}
+	if strings.EqualFold(uShape, "hexagon") {
+		return nil, fmt.Errorf("Exceeded allowed hexagon dims: %d\n", mapDim)
+	}
+	// by default we use 1:1:1 ratio of the map
+	ratios := []float64{1.0, 1.0, 1.0}
+	if dataLen >= 2 && dataDim >= 3 {
+		// missing values are replaced with feature means
+		data, err := fillMissing(data)
+		if err != nil {
+			return nil, err
+		}
+		_, eigVals, ok := stat.PrincipalComponents(data, nil)
+		if !ok {
+			return nil, fmt.Errorf("Could not determine Principal Components")
+		}
+		// side lengths are proportional to square roots of the eigenvalues
+		if eigVals[2] > 0 {
+			for i := range ratios {
+				ratios[i] = math.Sqrt(eigVals[i] / eigVals[2])
+			}
+		}
+	}
+	// the shortest side is chosen so that the map has roughly mUnits units
+	dim := math.Cbrt(mUnits / (ratios[0] * ratios[1]))
+	dims := make([]int, 3)
+	for i := range dims {
+		dims[i] = int(math.Max(1.0, math.Floor(dim*ratios[i])))
+	}
+	return dims, nil
+}
+
 // RandInit returns a matrix initialized to uniformly distributed random values
 // in each column in range between [max, min] where max and min are maximum and minmum values
 // in particular matrix column. The returned matrix has product(dims) number of rows and
@@ -113,11 +173,16 @@ func RandInit(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
 
 // LinInit returns a matrix initialized to values lying in a linear space
 // spanned by principal components of data stored in the data matrix passed in as parameter.
+// Missing values (NaN) are replaced with the means of the observed values of their features.
 // It fails with error if the new matrix could not be initialized or if data is nil.
 func LinInit(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
 	if err := validateLinInit(data, dims); err != nil {
 		return nil, err
 	}
+	data, err := fillMissing(data)
+	if err != nil {
+		return nil, err
+	}
 	// Adjust map dimensions size to account for 1D cases
 	mapDim := len(dims)
 	for _, dim := range dims {
@@ -125,6 +190,10 @@ func LinInit(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
 			mapDim--
 		}
 	}
+	// map can't span more dimensions than data
+	if _, dataDim := data.Dims(); mapDim > dataDim {
+		mapDim = dataDim
+	}
 	// calculate linear space basis
 	mapVecs, err := getBaseVecs(data, mapDim)
 	if err != nil {
@@ -203,6 +272,39 @@ func validateLinInit(data *mat64.Dense, dims []int) error {
 	return nil
 }
 
+// fillMissing returns a copy of data matrix whose missing values (NaN) are replaced with the
+// means of the observed values in their columns. Data without missing values is returned as is.
+// It returns error if any of the data columns has no observed values.
+func fillMissing(data *mat64.Dense) (*mat64.Dense, error) {
+	rows, cols := data.Dims()
+	missing := false
+	for i := 0; i < rows && !missing; i++ {
+		missing = hasMissing(data.RawRowView(i))
+	}
+	if !missing {
+		return data, nil
+	}
+	colsMean, err := matrix.ColsMean(cols, data)
+	if err != nil {
+		return nil, err
+	}
+	for j, mean := range colsMean {
+		if math.IsNaN(mean) {
+			return nil, fmt.Errorf("No observed values in data column: %d\n", j)
+		}
+	}
+	filled := mat64.DenseCopyOf(data)
+	for i := 0; i < rows; i++ {
+		row := filled.RawRowView(i)
+		for j := range row {
+			if math.IsNaN(row[j]) {
+				row[j] = colsMean[j]
+			}
+		}
+	}
+	return filled, nil
+}
+
 // getBaseVecs calculates linear space base vectors from the provided data
 // It returns a matrix that contains the linear space base vectors.
 // It fails with error if the principal components could not be found
@@ -239,6 +341,8 @@ func getBaseVecs(data *mat64.Dense, mapDim int) (*mat64.Dense, error) {
 }
 
 // getLinMapCoords calculates map coordinates and normalizes them to unit values
+// It returns a matrix which contains normalized coordinates along the first mapDim
+// map dimensions which are longer than 1 i.e. map dimensions spanning the map.
 // It returns error if it can't calculate coordinates
 func getLinMapCoords(mapDim int, dims []int) (*mat64.Dense, error) {
 	// calculate unit coordinates
@@ -248,29 +352,30 @@ func getLinMapCoords(mapDim int, dims []int) (*mat64.Dense, error) {
 	}
 	// swap x and y coordinates
 	mUnits := utils.IntProduct(dims)
-	x := make([]float64, mUnits)
-	y := make([]float64, mUnits)
-	mat64.Col(x, 0, coords)
-	mat64.Col(y, 1, coords)
-	coords.SetCol(0, y)
-	coords.SetCol(1, x)
-	// normalize coordinates to unit values
+	if len(dims) >= 2 {
+		x := make([]float64, mUnits)
+		y := make([]float64, mUnits)
+		mat64.Col(x, 0, coords)
+		mat64.Col(y, 1, coords)
+		coords.SetCol(0, y)
+		coords.SetCol(1, x)
+	}
+	// normalize coordinates of the spanning dimensions to unit values
+	m := mat64.NewDense(mUnits, mapDim, nil)
 	c := make([]float64, mUnits)
-	for i := 0; i < mapDim; i++ {
+	for i, col := 0, 0; i < len(dims) && col < mapDim; i++ {
+		if dims[i] == 1 {
+			continue
+		}
 		c = mat64.Col(c, i, coords)
 		max := floats.Max(c)
 		min := floats.Min(c)
-		if max > min {
-			floats.AddConst(-min, c)
-			floats.Scale(1.0/(max-min), c)
-		} else {
-			for j := 0; j < len(c); j++ {
-				c[j] = 0.5
-			}
-		}
-		coords.SetCol(i, c)
+		floats.AddConst(-min, c)
+		floats.Scale(1.0/(max-min), c)
+		m.SetCol(col, c)
+		col++
 	}
-	m, err := matrix.AddConst(-0.5, coords)
+	m, err = matrix.AddConst(-0.5, m)
 	if err != nil {
 		return nil, err
 	}
@@ -288,10 +393,6 @@ func GridCoords(uShape string, dims []int) (*mat64.Dense, error) {
 		return nil, err
 	}
 	mDims := len(dims)
-	// turn [n] -> [n,1]
-	if mDims == 1 {
-		dims = append(dims, 1)
-	}
 	// We need coordinates tuples to populate coords matrix
 	// cumprod will give us counts/length of the tuples of same numbers in each sequence
 	// dims will give us the the upper bound of the tuple sequence
@@ -305,14 +406,16 @@ func GridCoords(uShape string, dims []int) (*mat64.Dense, error) {
 		seq := makeSeq(mUnits/counts[i+1], dims[i], counts[i])
 		coords.SetCol(i, seq)
 	}
+	// 1D maps are simple chains of units
+	if mDims < 2 {
+		return coords, nil
+	}
 	// retrieve x and y coords
 	x := mat64.Col(make([]float64, mUnits), 0, coords)
 	y := mat64.Col(make([]float64, mUnits), 1, coords)
 	// swaps x and y coordinates: ij notation to xy
-	if mDims >= 2 {
-		coords.SetCol(1, x)
-		coords.SetCol(0, y)
-	}
+	coords.SetCol(1, x)
+	coords.SetCol(0, y)
 	// This will offset x-coordinates of every other unit by 0.5.
 	// This will make distances of a unit to all its six neighbors equal
 	if strings.EqualFold(uShape, "hexagon") {
@@ -336,6 +439,85 @@ func GridCoords(uShape string, dims []int) (*mat64.Dense, error) {
 	return coords, nil
 }
 
+// GridPeriods returns periods of SOM grid coordinates for the requested grid type,
+// unit shape and map dimensions. Period is the length of a grid coordinate axis after which
+// the grid wraps around. Zero period means the grid does not wrap around particular axis.
+// Periods are returned in the same order as the columns of the matrix returned by GridCoords.
+// Toroid grid wraps around all axes, cylinder grid wraps around the map dimension whose
+// index in dims slice is specified by axis parameter and planar grid does not wrap at all.
+// Axis parameter is ignored for all grid types other than cylinder.
+func GridPeriods(grid, uShape string, dims []int, axis int) []float64 {
+	periods := make([]float64, len(dims))
+	if grid != "toroid" && grid != "cylinder" {
+		return periods
+	}
+	for i, dim := range dims {
+		periods[i] = float64(dim)
+	}
+	// hexagon rows are sqrt(0.75) apart
+	if len(dims) >= 2 && strings.EqualFold(uShape, "hexagon") {
+		periods[0] *= math.Sqrt(0.75)
+	}
+	if grid == "cylinder" {
+		for i := range periods {
+			if i != axis {
+				periods[i] = 0.0
+			}
+		}
+	}
+	// GridCoords swaps the first two dimensions: ij notation to xy
+	if len(dims) >= 2 {
+		periods[0], periods[1] = periods[1], periods[0]
+	}
+	return periods
+}
+
+// GridDistMx calculates a distance matrix between SOM units whose coordinates are stored
+// in coords matrix rows using the given metric. Coordinate differences along axes with positive
+// periods wrap around i.e. they are never larger than half of the axis period.
+// It returns error if the metric is not supported, if coords matrix is nil or if the
+// number of periods does not match the number of coordinates.
+func GridDistMx(metric string, coords *mat64.Dense, periods []float64) (*mat64.Dense, error) {
+	distFn, ok := metricFunc(metric)
+	if !ok {
+		return nil, fmt.Errorf("Unsupported distance metric: %s\n", metric)
+	}
+	if coords == nil {
+		return nil, fmt.Errorf("Invalid coordinates matrix: %v\n", coords)
+	}
+	rows, cols := coords.Dims()
+	if len(periods) != cols {
+		return nil, fmt.Errorf("Incorrect number of periods: %d\n", len(periods))
+	}
+	out := mat64.NewDense(rows, rows, nil)
+	zero := mat64.NewVector(cols, nil)
+	diff := mat64.NewVector(cols, nil)
+	for row := 0; row < rows-1; row++ {
+		for i := row + 1; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				diff.SetVec(j, wrapDiff(coords.At(row, j), coords.At(i, j), periods[j]))
+			}
+			dist, err := distFn(diff, zero)
+			if err != nil {
+				return nil, err
+			}
+			out.Set(row, i, dist)
+			out.Set(i, row, dist)
+		}
+	}
+	return out, nil
+}
+
+// wrapDiff returns absolute difference between coordinates a and b.
+// If period is positive the difference wraps around it.
+func wrapDiff(a, b, period float64) float64 {
+	diff := math.Abs(a - b)
+	if period > 0 {
+		diff = math.Min(diff, period-diff)
+	}
+	return diff
+}
+
 // validate gridCoords validates whether you can initialize SOM unit coordinates
 // given the provided parameters. It returns error if the validation fails
 func validateGridCoords(uShape string, dims []int) error {
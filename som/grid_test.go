@@ -1,9 +1,11 @@
 package som
 
 import (
+	"math"
 	"testing"
 
 	"github.com/gonum/matrix/mat64"
+	"github.com/milosgajdos83/gosom/pkg/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -123,6 +125,50 @@ func TestLinInit(t *testing.T) {
 	assert.Error(err)
 }
 
+func TestInitMissing(t *testing.T) {
+	assert := assert.New(t)
+
+	nan := math.NaN()
+	data := mat64.NewDense(4, 2, []float64{
+		1.0, nan,
+		2.0, 4.0,
+		nan, 2.0,
+		3.0, 3.0,
+	})
+	for _, initFn := range []CodebookInitFunc{RandInit, LinInit} {
+		codebook, err := initFn(data, []int{2, 2})
+		assert.NoError(err)
+		for _, v := range codebook.RawMatrix().Data {
+			assert.False(math.IsNaN(v))
+		}
+	}
+	dims, err := GridDims(data, "rectangle")
+	assert.NoError(err)
+	assert.Len(dims, 2)
+	// missing values are replaced with column means
+	filled, err := fillMissing(data)
+	assert.NoError(err)
+	assert.Equal([]float64{
+		1.0, 3.0,
+		2.0, 4.0,
+		2.0, 2.0,
+		3.0, 3.0,
+	}, filled.RawMatrix().Data)
+	assert.True(math.IsNaN(data.At(0, 1)))
+	// complete data is not copied
+	complete := mat64.NewDense(2, 1, []float64{1, 2})
+	filled, err = fillMissing(complete)
+	assert.NoError(err)
+	assert.True(filled == complete)
+	// column without observed values
+	data.Set(1, 1, nan)
+	data.Set(2, 1, nan)
+	data.Set(3, 1, nan)
+	codebook, err := LinInit(data, []int{2, 2})
+	assert.Nil(codebook)
+	assert.EqualError(err, "No observed values in data column: 1\n")
+}
+
 func TestGridCoords(t *testing.T) {
 	assert := assert.New(t)
 
@@ -175,3 +221,256 @@ func TestGridCoords(t *testing.T) {
 	assert.Nil(coords)
 	assert.Error(err)
 }
+
+func TestGridPeriods(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		grid     string
+		uShape   string
+		dims     []int
+		axis     int
+		expected []float64
+	}{
+		{"planar", "hexagon", []int{4, 3}, 0, []float64{0, 0}},
+		{"planar", "rectangle", []int{4, 3}, 1, []float64{0, 0}},
+		{"toroid", "rectangle", []int{4, 3}, 0, []float64{3, 4}},
+		{"toroid", "hexagon", []int{4, 3}, 1, []float64{3, 4 * math.Sqrt(0.75)}},
+		{"cylinder", "rectangle", []int{4, 3}, 0, []float64{0, 4}},
+		{"cylinder", "rectangle", []int{4, 3}, 1, []float64{3, 0}},
+		{"cylinder", "hexagon", []int{4, 3}, 0, []float64{0, 4 * math.Sqrt(0.75)}},
+		{"cylinder", "hexagon", []int{4, 3}, 1, []float64{3, 0}},
+		{"cylinder", "rectangle", []int{4, 3, 2}, 2, []float64{0, 0, 2}},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(tc.expected, GridPeriods(tc.grid, tc.uShape, tc.dims, tc.axis))
+	}
+}
+
+// countNeighbs returns the number of units whose distance from unit is within (0, radius]
+func countNeighbs(gridDist *mat64.Dense, unit int, radius float64) int {
+	count := 0
+	_, cols := gridDist.Dims()
+	for i := 0; i < cols; i++ {
+		if d := gridDist.At(unit, i); i != unit && d <= radius+0.001 {
+			count++
+		}
+	}
+	return count
+}
+
+func TestGridDistMxToroid(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		uShape  string
+		dims    []int
+		neighbs int
+	}{
+		{"rectangle", []int{4, 5}, 4},
+		{"hexagon", []int{4, 5}, 6},
+		{"hexagon", []int{6, 3}, 6},
+	}
+
+	for _, tc := range testCases {
+		coords, err := GridCoords(tc.uShape, tc.dims)
+		assert.NoError(err)
+		periods := GridPeriods("toroid", tc.uShape, tc.dims, 0)
+		gridDist, err := GridDistMx("euclidean", coords, periods)
+		assert.NoError(err)
+		// every unit has the same number of immediate neighbours: there are no borders
+		mUnits, _ := coords.Dims()
+		for i := 0; i < mUnits; i++ {
+			assert.Equal(tc.neighbs, countNeighbs(gridDist, i, 1.0), tc.uShape)
+		}
+		// toroid distances are never larger than planar distances
+		planarDist, err := GridDistMx("euclidean", coords, GridPeriods("planar", tc.uShape, tc.dims, 0))
+		assert.NoError(err)
+		expPlanar, err := DistanceMx("euclidean", coords)
+		assert.NoError(err)
+		assert.True(mat64.EqualApprox(expPlanar, planarDist, 1e-9))
+		for i := 0; i < mUnits; i++ {
+			for j := 0; j < mUnits; j++ {
+				assert.True(gridDist.At(i, j) <= planarDist.At(i, j)+1e-9)
+			}
+		}
+	}
+
+	// opposite corners of a rectangle toroid are diagonal neighbours
+	coords, err := GridCoords("rectangle", []int{3, 4})
+	assert.NoError(err)
+	gridDist, err := GridDistMx("euclidean", coords, GridPeriods("toroid", "rectangle", []int{3, 4}, 0))
+	assert.NoError(err)
+	assert.InDelta(math.Sqrt2, gridDist.At(0, 11), 0.001)
+	gridDist, err = GridDistMx("chebyshev", coords, GridPeriods("toroid", "rectangle", []int{3, 4}, 0))
+	assert.NoError(err)
+	assert.InDelta(1.0, gridDist.At(0, 11), 0.001)
+	// unsupported metric
+	gridDist, err = GridDistMx("foobar", coords, []float64{0, 0})
+	assert.Nil(gridDist)
+	assert.Error(err)
+	// nil coordinates
+	gridDist, err = GridDistMx("euclidean", nil, []float64{0, 0})
+	assert.Nil(gridDist)
+	assert.Error(err)
+	// incorrect number of periods
+	gridDist, err = GridDistMx("euclidean", coords, []float64{0})
+	assert.Nil(gridDist)
+	assert.Error(err)
+}
+
+func TestGridDistMxCylinder(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		uShape  string
+		dims    []int
+		axis    int
+		neighbs int
+	}{
+		{"rectangle", []int{4, 5}, 0, 4},
+		{"rectangle", []int{4, 5}, 1, 4},
+		{"hexagon", []int{4, 5}, 0, 6},
+		{"hexagon", []int{4, 5}, 1, 6},
+	}
+
+	for _, tc := range testCases {
+		coords, err := GridCoords(tc.uShape, tc.dims)
+		assert.NoError(err)
+		periods := GridPeriods("cylinder", tc.uShape, tc.dims, tc.axis)
+		gridDist, err := GridDistMx("euclidean", coords, periods)
+		assert.NoError(err)
+		// units along the non-wrapped dimension edges have fewer neighbours
+		mUnits, _ := coords.Dims()
+		rows, cols := tc.dims[0], tc.dims[1]
+		for i := 0; i < mUnits; i++ {
+			row, col := i%rows, i/rows
+			edge := (tc.axis == 0 && (col == 0 || col == cols-1)) ||
+				(tc.axis == 1 && (row == 0 || row == rows-1))
+			if edge {
+				assert.True(countNeighbs(gridDist, i, 1.0) < tc.neighbs, tc.uShape)
+			} else {
+				assert.Equal(tc.neighbs, countNeighbs(gridDist, i, 1.0), tc.uShape)
+			}
+		}
+	}
+
+	// first and last column of a cylinder wrapped around columns are neighbours
+	dims := []int{3, 4}
+	coords, err := GridCoords("rectangle", dims)
+	assert.NoError(err)
+	gridDist, err := GridDistMx("euclidean", coords, GridPeriods("cylinder", "rectangle", dims, 1))
+	assert.NoError(err)
+	assert.InDelta(1.0, gridDist.At(0, 9), 0.001)
+	assert.InDelta(2.0, gridDist.At(0, 2), 0.001)
+	// first and last row of a cylinder wrapped around rows are neighbours
+	gridDist, err = GridDistMx("euclidean", coords, GridPeriods("cylinder", "rectangle", dims, 0))
+	assert.NoError(err)
+	assert.InDelta(1.0, gridDist.At(0, 2), 0.001)
+	assert.InDelta(3.0, gridDist.At(0, 9), 0.001)
+}
+
+func TestGridDimsN(t *testing.T) {
+	assert := assert.New(t)
+
+	data := mat64.NewDense(6, 4, []float64{
+		5.1, 3.5, 1.4, 0.2,
+		4.9, 3.0, 1.4, 0.2,
+		4.7, 3.2, 1.3, 0.2,
+		4.6, 3.1, 1.5, 0.2,
+		5.0, 3.6, 1.4, 0.2,
+		5.4, 3.9, 1.7, 0.4,
+	})
+	// 1D map is a chain of units
+	dims, err := GridDimsN(data, "rectangle", 1)
+	assert.NoError(err)
+	assert.EqualValues([]int{13}, dims)
+	// 2D map dimensions match GridDims
+	dims, err = GridDimsN(data, "hexagon", 2)
+	assert.NoError(err)
+	expDims, err := GridDims(data, "hexagon")
+	assert.NoError(err)
+	assert.EqualValues(expDims, dims)
+	// 3D map dimensions are ordered by data variance
+	dims, err = GridDimsN(data, "rectangle", 3)
+	assert.NoError(err)
+	assert.Len(dims, 3)
+	assert.True(dims[0] >= dims[1] && dims[1] >= dims[2])
+	for _, dim := range dims {
+		assert.True(dim >= 1)
+	}
+	// 3D map from data with fewer dimensions is a cube
+	data = mat64.NewDense(8, 2, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	dims, err = GridDimsN(data, "rectangle", 3)
+	assert.NoError(err)
+	assert.EqualValues([]int{2, 2, 2}, dims)
+	// 3D hexagon maps are not supported
+	dims, err = GridDimsN(data, "hexagon", 3)
+	assert.Nil(dims)
+	assert.Error(err)
+	// unsupported number of dimensions
+	dims, err = GridDimsN(data, "rectangle", 4)
+	assert.Nil(dims)
+	assert.Error(err)
+	// data matrix can't be nil
+	dims, err = GridDimsN(nil, "rectangle", 1)
+	assert.Nil(dims)
+	assert.Error(err)
+}
+
+func TestGridCoords1D3D(t *testing.T) {
+	assert := assert.New(t)
+
+	// 1D maps are chains
+	for _, uShape := range []string{"hexagon", "rectangle"} {
+		coords, err := GridCoords(uShape, []int{4})
+		assert.NoError(err)
+		expMx := mat64.NewDense(4, 1, []float64{0.0, 1.0, 2.0, 3.0})
+		assert.True(mat64.Equal(expMx, coords))
+	}
+	// 3D maps are rectangular lattices
+	coords, err := GridCoords("rectangle", []int{2, 2, 2})
+	assert.NoError(err)
+	expMx := mat64.NewDense(8, 3, []float64{
+		0.0, 0.0, 0.0,
+		0.0, 1.0, 0.0,
+		1.0, 0.0, 0.0,
+		1.0, 1.0, 0.0,
+		0.0, 0.0, 1.0,
+		0.0, 1.0, 1.0,
+		1.0, 0.0, 1.0,
+		1.0, 1.0, 1.0,
+	})
+	assert.True(mat64.Equal(expMx, coords))
+	// 3D hexagon maps are not supported
+	coords, err = GridCoords("hexagon", []int{2, 2, 2})
+	assert.Nil(coords)
+	assert.Error(err)
+}
+
+func TestLinInit1D3D(t *testing.T) {
+	assert := assert.New(t)
+
+	inMx := mat64.NewDense(6, 4, []float64{
+		5.1, 3.5, 1.4, 0.2,
+		4.9, 3.0, 1.4, 0.2,
+		4.7, 3.2, 1.3, 0.2,
+		4.6, 3.1, 1.5, 0.2,
+		5.0, 3.6, 1.4, 0.2,
+		5.4, 3.9, 1.7, 0.4,
+	})
+	for _, dims := range [][]int{{6}, {1, 6}, {6, 1}, {3, 2, 2}} {
+		linMx, err := LinInit(inMx, dims)
+		assert.NoError(err)
+		rows, cols := linMx.Dims()
+		assert.Equal(utils.IntProduct(dims), rows)
+		assert.Equal(4, cols)
+		// all codebook vectors are different
+		for i := 0; i < rows; i++ {
+			for j := i + 1; j < rows; j++ {
+				assert.False(mat64.Equal(linMx.RowView(i), linMx.RowView(j)), "%v", dims)
+			}
+		}
+	}
+}
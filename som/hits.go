@@ -0,0 +1,75 @@
+package som
+
+import (
+	"fmt"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// Hits returns a slice which contains hit counts of all SOM units. Hit count of a SOM unit
+// is the number of input vectors whose BMU is the SOM unit: see BMUs.
+func (m Map) Hits() []int {
+	return hits(m.bmus, m.grid.Units())
+}
+
+// HitsFor returns a slice which contains hit counts of all SOM units for the data samples
+// stored in rows of data matrix. Hit count of a SOM unit is the number of data samples
+// whose BMU is the SOM unit.
+// It returns error if data is nil or if its dimension does not match the codebook dimension.
+func (m Map) HitsFor(data *mat64.Dense) ([]int, error) {
+	bmus, _, err := m.BMUsFor(data)
+	if err != nil {
+		return nil, err
+	}
+	return hits(bmus, m.grid.Units()), nil
+}
+
+// SamplesOf returns indices of the input vectors whose BMU is unit: see BMUs.
+// It returns error if the unit index is invalid.
+func (m Map) SamplesOf(unit int) ([]int, error) {
+	if unit < 0 || unit >= m.grid.Units() {
+		return nil, fmt.Errorf("Invalid unit index: %d\n", unit)
+	}
+	samples := []int{}
+	for i, bmu := range m.bmus {
+		if bmu == unit {
+			samples = append(samples, i)
+		}
+	}
+	return samples, nil
+}
+
+// EmptyUnits returns indices of SOM units which are not BMUs of any input vector: see BMUs.
+func (m Map) EmptyUnits() []int {
+	empty := []int{}
+	for unit, count := range m.Hits() {
+		if count == 0 {
+			empty = append(empty, unit)
+		}
+	}
+	return empty
+}
+
+// SampleClusters returns a slice which contains cluster labels of all input vectors given
+// the cluster labels of SOM units: input vectors are assigned the labels of their BMUs.
+// You can cluster SOM codebook vectors using the clustering algorithms in cluster package.
+// It returns error if the number of unit labels does not match the number of SOM units.
+func (m Map) SampleClusters(unitClusters []int) ([]int, error) {
+	if len(unitClusters) != m.grid.Units() {
+		return nil, fmt.Errorf("Incorrect number of unit clusters: %d\n", len(unitClusters))
+	}
+	clusters := make([]int, len(m.bmus))
+	for i, bmu := range m.bmus {
+		clusters[i] = unitClusters[bmu]
+	}
+	return clusters, nil
+}
+
+// hits returns a slice which contains the number of occurrences of all SOM units in bmus
+func hits(bmus []int, mUnits int) []int {
+	counts := make([]int, mUnits)
+	for _, bmu := range bmus {
+		counts[bmu]++
+	}
+	return counts
+}
@@ -0,0 +1,110 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/milosgajdos83/gosom/pkg/cluster"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHits(t *testing.T) {
+	assert := assert.New(t)
+
+	data := mat64.NewDense(6, 1, []float64{0.1, 0.2, 2.9, 3.1, 6.2, 0.0})
+	codebook := mat64.NewDense(4, 1, []float64{0, 1, 3, 6})
+	m, err := newUMatrixMap("planar", "rectangle", []int{4}, codebook)
+	assert.NoError(err)
+	// map is created with the data samples mapped to their BMUs
+	m, err = NewMap(&m.config, data)
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 2, 2, 3, 0}, m.BMUs())
+	assert.Equal([]int{3, 0, 2, 1}, m.Hits())
+	assert.Equal([]int{1}, m.EmptyUnits())
+
+	testCases := []struct {
+		unit    int
+		samples []int
+	}{
+		{0, []int{0, 1, 5}},
+		{1, []int{}},
+		{2, []int{2, 3}},
+		{3, []int{4}},
+	}
+	for _, tc := range testCases {
+		samples, err := m.SamplesOf(tc.unit)
+		assert.NoError(err)
+		assert.Equal(tc.samples, samples)
+	}
+	for _, unit := range []int{-1, 4} {
+		samples, err := m.SamplesOf(unit)
+		assert.Nil(samples)
+		assert.Error(err)
+	}
+
+	// hits for other data
+	hits, err := m.HitsFor(mat64.NewDense(3, 1, []float64{0.9, 1.1, 5}))
+	assert.NoError(err)
+	assert.Equal([]int{0, 2, 0, 1}, hits)
+	hits, err = m.HitsFor(nil)
+	assert.Nil(hits)
+	assert.Error(err)
+	hits, err = m.HitsFor(mat64.NewDense(1, 2, nil))
+	assert.Nil(hits)
+	assert.Error(err)
+}
+
+func TestHitsTrain(t *testing.T) {
+	assert := assert.New(t)
+
+	c := *cSom
+	c.Radius = 1
+	m, err := NewMap(&c, dataMx)
+	assert.NoError(err)
+	assert.NoError(m.TrainBatch(dataMx, 5))
+	rows, _ := dataMx.Dims()
+	// hits add up to the number of samples
+	total := 0
+	for unit, count := range m.Hits() {
+		total += count
+		samples, err := m.SamplesOf(unit)
+		assert.NoError(err)
+		assert.Len(samples, count)
+		for _, sample := range samples {
+			assert.Equal(unit, m.BMUs()[sample])
+		}
+	}
+	assert.Equal(rows, total)
+	hits, err := m.HitsFor(dataMx)
+	assert.NoError(err)
+	assert.Equal(m.Hits(), hits)
+	for _, unit := range m.EmptyUnits() {
+		assert.Equal(0, m.Hits()[unit])
+	}
+}
+
+func TestSampleClusters(t *testing.T) {
+	assert := assert.New(t)
+
+	data := mat64.NewDense(6, 1, []float64{0.1, 0.2, 2.9, 3.1, 6.2, 0.0})
+	codebook := mat64.NewDense(4, 1, []float64{0, 1, 3, 6})
+	m, err := newUMatrixMap("planar", "rectangle", []int{4}, codebook)
+	assert.NoError(err)
+	m, err = NewMap(&m.config, data)
+	assert.NoError(err)
+	clusters, err := m.SampleClusters([]int{0, 0, 1, 1})
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 1, 1, 1, 0}, clusters)
+
+	// cluster codebook vectors of neighbouring units
+	unitClusters, err := cluster.Agglomerative(m.Codebook(), 2, "ward", m.Grid().Connectivity())
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 0, 1}, unitClusters)
+	clusters, err = m.SampleClusters(unitClusters)
+	assert.NoError(err)
+	assert.Equal([]int{0, 0, 0, 0, 1, 0}, clusters)
+
+	clusters, err = m.SampleClusters([]int{0, 1})
+	assert.Nil(clusters)
+	assert.Error(err)
+}
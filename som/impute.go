@@ -0,0 +1,133 @@
+package som
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// Impute returns a copy of data matrix whose missing values (NaN) are replaced with the values
+// estimated by the map. BMU of every data sample is found using only its observed features.
+// If radius is zero, missing values are copied from the BMU codebook vector, otherwise they
+// are copied from the average of codebook vectors weighted by the map neighbourhood function
+// evaluated on their grid distance from the BMU with the given radius.
+// It returns error if data is nil, if its dimension does not match the codebook dimension,
+// if radius is negative or if any of the data samples has no observed features.
+func (m Map) Impute(data *mat64.Dense, radius float64) (*mat64.Dense, error) {
+	if radius < 0 {
+		return nil, fmt.Errorf("Invalid imputation radius: %f\n", radius)
+	}
+	bmus, _, err := m.BMUsFor(data)
+	if err != nil {
+		return nil, err
+	}
+	imputed := mat64.DenseCopyOf(data)
+	for i, bmu := range bmus {
+		row := imputed.RawRowView(i)
+		if !hasMissing(row) {
+			continue
+		}
+		if len(observedFeatures(row)) == 0 {
+			return nil, fmt.Errorf("No observed features in data sample: %d\n", i)
+		}
+		estimate := m.codebook.RawRowView(bmu)
+		if radius > 0 {
+			estimate = m.neighbMean(bmu, radius)
+		}
+		for k := range row {
+			if math.IsNaN(row[k]) {
+				row[k] = estimate[k]
+			}
+		}
+	}
+	return imputed, nil
+}
+
+// neighbMean returns the average of codebook vectors weighted by the map neighbourhood function
+// evaluated on their grid distance from unit with the given radius. If all the weights are
+// zero, the codebook vector of unit is returned.
+func (m Map) neighbMean(unit int, radius float64) []float64 {
+	neighbFn := Neighb[m.config.NeighbFn]
+	mUnits, cols := m.codebook.Dims()
+	gridDist := m.grid.row(unit)
+	mean := make([]float64, cols)
+	weight := 0.0
+	for j := 0; j < mUnits; j++ {
+		h := neighbFn(gridDist[j], radius)
+		if h == 0 {
+			continue
+		}
+		floats.AddScaled(mean, h, m.codebook.RawRowView(j))
+		weight += h
+	}
+	if weight == 0 {
+		return m.codebook.RawRowView(unit)
+	}
+	floats.Scale(1/weight, mean)
+	return mean
+}
+
+// observedDists stores distances between vector x with missing values (NaN) and rows of codebook
+// matrix in dists. Distances are computed using the requested metric on the observed features
+// of x only; euclidean and sqeuclidean distances are squared as in distMx.
+// If x has no observed features, all the distances are zero.
+// It returns error if the requested metric is not supported.
+func observedDists(metric string, x []float64, codebook *mat64.Dense, dists []float64) error {
+	observed := observedFeatures(x)
+	if len(observed) == 0 {
+		for j := range dists {
+			dists[j] = 0.0
+		}
+		return nil
+	}
+	xObs := make([]float64, len(observed))
+	wObs := make([]float64, len(observed))
+	for k, col := range observed {
+		xObs[k] = x[col]
+	}
+	squared := metric == "euclidean" || metric == "sqeuclidean"
+	distFn, ok := metricFunc(metric)
+	if !ok && !squared {
+		return fmt.Errorf("Unsupported distance metric: %s\n", metric)
+	}
+	for j := range dists {
+		w := codebook.RawRowView(j)
+		for k, col := range observed {
+			wObs[k] = w[col]
+		}
+		if squared {
+			dist := floats.Distance(xObs, wObs, 2)
+			dists[j] = dist * dist
+			continue
+		}
+		dist, err := distFn(mat64.NewVector(len(xObs), xObs), mat64.NewVector(len(wObs), wObs))
+		if err != nil {
+			return err
+		}
+		dists[j] = dist
+	}
+	return nil
+}
+
+// observedFeatures returns indices of the elements of x which are not missing (NaN)
+func observedFeatures(x []float64) []int {
+	observed := []int{}
+	for k, v := range x {
+		if !math.IsNaN(v) {
+			observed = append(observed, k)
+		}
+	}
+	return observed
+}
+
+// hasMissing returns true if any of the elements of x is missing (NaN)
+func hasMissing(x []float64) bool {
+	for _, v := range x {
+		if math.IsNaN(v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package som
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImpute(t *testing.T) {
+	assert := assert.New(t)
+
+	codebook := mat64.NewDense(4, 2, []float64{
+		0.0, 0.0,
+		0.0, 1.0,
+		1.0, 0.0,
+		1.0, 1.0,
+	})
+	m, err := newUMatrixMap("planar", "rectangle", []int{2, 2}, codebook)
+	assert.NoError(err)
+	m.config.NeighbFn = "bubble"
+	nan := math.NaN()
+	data := mat64.NewDense(3, 2, []float64{
+		nan, 0.9,
+		0.8, nan,
+		0.3, 0.3,
+	})
+
+	testCases := []struct {
+		radius  float64
+		imputed []float64
+	}{
+		// missing values are copied from BMUs
+		{0.0, []float64{
+			0.0, 0.9,
+			0.8, 0.0,
+			0.3, 0.3}},
+		// missing values are copied from the average of BMUs and their adjacent units
+		{1.0, []float64{
+			1.0 / 3, 0.9,
+			0.8, 1.0 / 3,
+			0.3, 0.3}},
+	}
+	for _, tc := range testCases {
+		imputed, err := m.Impute(data, tc.radius)
+		assert.NoError(err)
+		for i, v := range imputed.RawMatrix().Data {
+			assert.InDelta(tc.imputed[i], v, 1e-9)
+		}
+	}
+	// data matrix is not modified
+	assert.True(math.IsNaN(data.At(0, 0)))
+	assert.True(math.IsNaN(data.At(1, 1)))
+
+	errCases := []struct {
+		data   *mat64.Dense
+		radius float64
+	}{
+		{nil, 0.0},
+		{mat64.NewDense(1, 3, nil), 0.0},
+		{data, -1.0},
+		{mat64.NewDense(2, 2, []float64{0.1, 0.2, nan, nan}), 0.0},
+	}
+	for _, tc := range errCases {
+		imputed, err := m.Impute(tc.data, tc.radius)
+		assert.Nil(imputed)
+		assert.Error(err)
+	}
+}
+
+func TestImputeLayers(t *testing.T) {
+	assert := assert.New(t)
+
+	// targets of data samples are imputed from the output layer of their BMUs
+	codebook := mat64.NewDense(2, 3, []float64{
+		0.0, 0.0, 1.0,
+		1.0, 1.0, 0.0,
+	})
+	m, err := newLayerMap([]Layer{{Dim: 2, Weight: 0.5}, {Dim: 1, Weight: 0.5}}, codebook)
+	assert.NoError(err)
+	nan := math.NaN()
+	data := mat64.NewDense(2, 3, []float64{
+		0.1, 0.2, nan,
+		0.9, 0.7, nan,
+	})
+	imputed, err := m.Impute(data, 0.0)
+	assert.NoError(err)
+	assert.Equal([]float64{
+		0.1, 0.2, 1.0,
+		0.9, 0.7, 0.0,
+	}, imputed.RawMatrix().Data)
+}
@@ -0,0 +1,107 @@
+package som
+
+import (
+	"fmt"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// LabelFreqs returns a slice which contains label frequencies of all SOM units for the data
+// samples stored in rows of data matrix labeled with labels. Frequency of a label at a SOM
+// unit is the share of the data samples mapped to the unit which have that label.
+// Frequencies of the units which are not BMUs of any data sample are empty.
+// It returns error if data is nil, if its dimension does not match the codebook dimension,
+// if the number of labels does not match the number of data samples or if any label is empty.
+func (m Map) LabelFreqs(data *mat64.Dense, labels []string) ([]map[string]float64, error) {
+	bmus, err := m.labeledBMUs(data, labels)
+	if err != nil {
+		return nil, err
+	}
+	freqs := make([]map[string]float64, m.grid.Units())
+	for unit := range freqs {
+		freqs[unit] = make(map[string]float64)
+	}
+	for i, bmu := range bmus {
+		freqs[bmu][labels[i]]++
+	}
+	for unit, count := range hits(bmus, m.grid.Units()) {
+		for label := range freqs[unit] {
+			freqs[unit][label] /= float64(count)
+		}
+	}
+	return freqs, nil
+}
+
+// MajorityLabels returns a slice which contains labels of all SOM units assigned by majority
+// vote of the data samples stored in rows of data matrix labeled with labels: each SOM unit is
+// labeled with the most frequent label of the data samples mapped to it. Ties are broken in
+// favour of the label which comes first in lexical order. The units which are not BMUs of any
+// data sample are labeled with empty string, which is not a valid label.
+// It returns error if data is nil, if its dimension does not match the codebook dimension,
+// if the number of labels does not match the number of data samples or if any label is empty.
+func (m Map) MajorityLabels(data *mat64.Dense, labels []string) ([]string, error) {
+	freqs, err := m.LabelFreqs(data, labels)
+	if err != nil {
+		return nil, err
+	}
+	unitLabels := make([]string, len(freqs))
+	for unit, unitFreqs := range freqs {
+		maxFreq := 0.0
+		for label, freq := range unitFreqs {
+			if freq > maxFreq || (freq == maxFreq && label < unitLabels[unit]) {
+				unitLabels[unit], maxFreq = label, freq
+			}
+		}
+	}
+	return unitLabels, nil
+}
+
+// NearestLabels returns a slice which contains labels of all SOM units assigned from the data
+// samples stored in rows of data matrix labeled with labels: each SOM unit is labeled with the
+// label of the data sample closest to its codebook vector, so all units are labeled.
+// It returns error if data is nil, if its dimension does not match the codebook dimension,
+// if the number of labels does not match the number of data samples or if any label is empty.
+func (m Map) NearestLabels(data *mat64.Dense, labels []string) ([]string, error) {
+	if err := validateLabels(data, labels); err != nil {
+		return nil, err
+	}
+	nearest, _, err := closestRows(m.config.Metric, m.codebook, data, sqNorms(data))
+	if err != nil {
+		return nil, err
+	}
+	unitLabels := make([]string, len(nearest))
+	for unit, sample := range nearest {
+		unitLabels[unit] = labels[sample]
+	}
+	return unitLabels, nil
+}
+
+// labeledBMUs returns BMUs of the data samples stored in rows of data matrix labeled with labels.
+// It returns error if data is nil, if its dimension does not match the codebook dimension,
+// if the number of labels does not match the number of data samples or if any label is empty.
+func (m Map) labeledBMUs(data *mat64.Dense, labels []string) ([]int, error) {
+	if err := validateLabels(data, labels); err != nil {
+		return nil, err
+	}
+	bmus, _, err := m.BMUsFor(data)
+	return bmus, err
+}
+
+// validateLabels checks if the data samples stored in rows of data matrix can be labeled with labels.
+// Empty string is reserved for the units which are not labeled, so it is not a valid label.
+// It returns error if data is nil, if the number of labels does not match the number of data samples
+// or if any of the labels is empty.
+func validateLabels(data *mat64.Dense, labels []string) error {
+	if data == nil {
+		return fmt.Errorf("Invalid data matrix: %v\n", data)
+	}
+	if rows, _ := data.Dims(); len(labels) != rows {
+		return fmt.Errorf("Incorrect number of labels: %d\n", len(labels))
+	}
+	for i, label := range labels {
+		if label == "" {
+			return fmt.Errorf("Empty label of data sample: %d\n", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabels(t *testing.T) {
+	assert := assert.New(t)
+
+	codebook := mat64.NewDense(4, 1, []float64{0, 1, 3, 6})
+	m, err := newUMatrixMap("planar", "rectangle", []int{4}, codebook)
+	assert.NoError(err)
+	data := mat64.NewDense(6, 1, []float64{0.1, 0.2, -0.1, 2.95, 3.1, 6.2})
+	labels := []string{"a", "a", "b", "b", "c", "c"}
+
+	freqs, err := m.LabelFreqs(data, labels)
+	assert.NoError(err)
+	assert.Len(freqs, 4)
+	assert.Len(freqs[0], 2)
+	assert.InDelta(2.0/3, freqs[0]["a"], 1e-9)
+	assert.InDelta(1.0/3, freqs[0]["b"], 1e-9)
+	// unit 1 is not BMU of any sample
+	assert.Equal(map[string]float64{}, freqs[1])
+	assert.Equal(map[string]float64{"b": 0.5, "c": 0.5}, freqs[2])
+	assert.Equal(map[string]float64{"c": 1.0}, freqs[3])
+
+	majority, err := m.MajorityLabels(data, labels)
+	assert.NoError(err)
+	// ties are broken by lexical order
+	assert.Equal([]string{"a", "", "b", "c"}, majority)
+
+	nearest, err := m.NearestLabels(data, labels)
+	assert.NoError(err)
+	assert.Equal([]string{"a", "a", "b", "c"}, nearest)
+
+	errCases := []struct {
+		data   *mat64.Dense
+		labels []string
+	}{
+		{nil, labels},
+		{data, labels[1:]},
+		{mat64.NewDense(6, 2, nil), labels},
+		// empty string is reserved for unlabeled units
+		{data, []string{"a", "a", "", "b", "c", "c"}},
+	}
+	for _, tc := range errCases {
+		freqs, err := m.LabelFreqs(tc.data, tc.labels)
+		assert.Nil(freqs)
+		assert.Error(err)
+		majority, err := m.MajorityLabels(tc.data, tc.labels)
+		assert.Nil(majority)
+		assert.Error(err)
+		nearest, err := m.NearestLabels(tc.data, tc.labels)
+		assert.Nil(nearest)
+		assert.Error(err)
+	}
+}
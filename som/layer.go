@@ -0,0 +1,336 @@
+package som
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// Layer is a layer of SOM codebook. Supervised SOMs, such as XYF maps, have codebooks with
+// several layers, e.g. an input layer which holds data features and an output layer which
+// holds targets such as one-hot encoded classes (see OneHot) or numeric values.
+// Data sample features and codebook vector elements are split into consecutive layers in the
+// order the layers are configured. BMU search uses a weighted sum of the layer distances
+// divided by the layer scales, whereas training updates all layers alike, so the map is
+// organised by all of them. Dividing the distances by the layer scales, as R kohonen package
+// does, makes the layers comparable, so the weights control the influence of the layers.
+// All distances between data samples and codebook vectors are measured in the same way as in
+// BMU search, so quantization, topographic and combined errors, distortion and predictors are
+// consistent with BMUs of the map. Distances between codebook vectors in combined error paths
+// are measured in the same way too. U-matrix, P-matrix, topographic product, trustworthiness
+// and continuity use the map distance metric on whole vectors.
+type Layer struct {
+	// Dim specifies the number of data features in the layer
+	Dim int
+	// Weight specifies weight of the layer distance in BMU search.
+	// Weights of all layers are normalized to sum up to 1
+	Weight float64
+	// Metric specifies distance metric of the layer: map distance metric by default
+	Metric string
+	// Scale specifies typical distance in the layer which the layer distances are divided by.
+	// If it is zero, it is set to the mean distance between training data samples in the layer
+	Scale float64
+}
+
+// layerScaleSamples is the maximum number of data samples used to compute layer scales
+const layerScaleSamples = 1000
+
+// Layers returns codebook layers of the map with normalized weights and scales.
+// Maps configured without layers have a single layer.
+func (m Map) Layers() []Layer {
+	return m.layers
+}
+
+// Layer returns a matrix which contains codebook vector elements of the requested layer.
+// It returns error if the layer index is invalid.
+func (m Map) Layer(layer int) (*mat64.Dense, error) {
+	codebook, err := m.layerCodebook(layer)
+	if err != nil {
+		return nil, err
+	}
+	return mat64.DenseCopyOf(codebook), nil
+}
+
+// LayerBMUs returns indices of the Best Match Units (BMUs) of all vectors stored in rows of
+// data matrix along with their distances from the BMUs found using only the requested layer.
+// Data matrix must contain only the features of the layer, e.g. data samples without targets.
+// It returns error if the layer index is invalid, if data is nil or if its dimension
+// does not match the layer dimension.
+func (m Map) LayerBMUs(data *mat64.Dense, layer int) ([]int, []float64, error) {
+	codebook, err := m.layerCodebook(layer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return closestRows(m.layers[layer].Metric, data, codebook, sqNorms(codebook))
+}
+
+// PredictLayer returns a matrix which contains predicted values of layer to for the vectors
+// stored in rows of data matrix which contain the features of layer from. Predicted values
+// are the codebook vector elements of layer to of the BMUs found using layer from: see LayerBMUs.
+// E.g. XYF map with features in layer 0 and targets in layer 1 predicts targets of data
+// samples using PredictLayer(data, 0, 1).
+// It returns error if any of the layer indices is invalid, if data is nil or if its
+// dimension does not match the dimension of layer from.
+func (m Map) PredictLayer(data *mat64.Dense, from, to int) (*mat64.Dense, error) {
+	codebook, err := m.layerCodebook(to)
+	if err != nil {
+		return nil, err
+	}
+	bmus, _, err := m.LayerBMUs(data, from)
+	if err != nil {
+		return nil, err
+	}
+	_, cols := codebook.Dims()
+	predicted := mat64.NewDense(len(bmus), cols, nil)
+	for i, bmu := range bmus {
+		predicted.SetRow(i, codebook.RawRowView(bmu))
+	}
+	return predicted, nil
+}
+
+// OneHot returns a matrix which contains one-hot encoded labels along with the lexically
+// ordered class labels of its columns. Rows of the returned matrix correspond to labels:
+// element (i, j) is 1 if the i-th label is the j-th class label and 0 otherwise.
+// It returns error if no labels are supplied.
+func OneHot(labels []string) (*mat64.Dense, []string, error) {
+	if len(labels) == 0 {
+		return nil, nil, fmt.Errorf("Incorrect number of labels: %d\n", len(labels))
+	}
+	classes := uniqueLabels(labels)
+	index := make(map[string]int)
+	for i, class := range classes {
+		index[class] = i
+	}
+	encoded := mat64.NewDense(len(labels), len(classes), nil)
+	for i, label := range labels {
+		encoded.Set(i, index[label], 1.0)
+	}
+	return encoded, classes, nil
+}
+
+// closestUnits returns indices of the BMUs of the vectors stored in rows of data matrix along
+// with their distances from the BMUs. Distances of multi-layer maps are weighted sums of the
+// layer distances. cbNorms must contain squared norms of the codebook vectors.
+// It returns error if data is nil or if its dimension does not match the codebook dimension.
+func (m Map) closestUnits(data *mat64.Dense, cbNorms []float64) ([]int, []float64, error) {
+	if len(m.layers) < 2 {
+		return closestRows(m.config.Metric, data, m.codebook, cbNorms)
+	}
+	if err := validateDims(data, m.codebook); err != nil {
+		return nil, nil, err
+	}
+	return closestBlocks(data, func(block *mat64.Dense) (*mat64.Dense, error) {
+		return m.unitDists(block, cbNorms)
+	})
+}
+
+// unitDists returns a matrix of distances between rows of data matrix and codebook vectors.
+// Distances of single layer maps are computed using distMx and distances of multi-layer maps
+// using layerDistMx. cbNorms must contain squared norms of the codebook vectors.
+// It returns error if the distance metric is not supported.
+func (m Map) unitDists(data *mat64.Dense, cbNorms []float64) (*mat64.Dense, error) {
+	if len(m.layers) < 2 {
+		return distMx(m.config.Metric, data, m.codebook, cbNorms)
+	}
+	return layerDistMx(m.layers, data, m.codebook)
+}
+
+// unitBlocks computes distances between blocks of data rows and codebook vectors using unitDists
+// and calls fn with the index of the first row in the block and the block distance matrix.
+// It returns error if data is nil, if its dimension does not match the codebook dimension
+// or if the distance metric is not supported.
+func (m Map) unitBlocks(data *mat64.Dense, fn func(int, *mat64.Dense)) error {
+	if err := validateDims(data, m.codebook); err != nil {
+		return err
+	}
+	cbNorms := sqNorms(m.codebook)
+	return forBlocks(data, func(block *mat64.Dense) (*mat64.Dense, error) {
+		return m.unitDists(block, cbNorms)
+	}, fn)
+}
+
+// codebookDist returns distance between codebook vectors of units i and j measured using the map
+// distance metric on single layer maps and as a weighted sum of layer distances on multi-layer
+// maps: see layerDistMx.
+// It returns error if the distance metric is not supported.
+func (m Map) codebookDist(i, j int) (float64, error) {
+	if len(m.layers) < 2 {
+		distFn, ok := metricFunc(m.config.Metric)
+		if !ok {
+			return 0.0, fmt.Errorf("Unsupported distance metric: %s\n", m.config.Metric)
+		}
+		return distFn(m.codebook.RowView(i), m.codebook.RowView(j))
+	}
+	_, cols := m.codebook.Dims()
+	a := m.codebook.View(i, 0, 1, cols).(*mat64.Dense)
+	b := m.codebook.View(j, 0, 1, cols).(*mat64.Dense)
+	dists, err := layerDistMx(m.layers, a, b)
+	if err != nil {
+		return 0.0, err
+	}
+	return dists.At(0, 0), nil
+}
+
+// layerCodebook returns a view of codebook vector elements of the requested layer.
+// It returns error if the layer index is invalid.
+func (m Map) layerCodebook(layer int) (*mat64.Dense, error) {
+	if layer < 0 || layer >= len(m.layers) {
+		return nil, fmt.Errorf("Invalid layer index: %d\n", layer)
+	}
+	from := 0
+	for i := 0; i < layer; i++ {
+		from += m.layers[i].Dim
+	}
+	mUnits, _ := m.codebook.Dims()
+	return m.codebook.View(0, from, mUnits, m.layers[layer].Dim).(*mat64.Dense), nil
+}
+
+// layerDistMx returns a matrix of weighted sums of layer distances between rows of data matrix
+// and rows of codebook matrix divided by the layer scales. Unlike distMx it returns Euclidean
+// distances for euclidean layers. Layers with zero weight are skipped.
+// It returns error if any of the layer distance metrics is not supported.
+func layerDistMx(layers []Layer, data, codebook *mat64.Dense) (*mat64.Dense, error) {
+	rows, _ := data.Dims()
+	mUnits, _ := codebook.Dims()
+	dists := mat64.NewDense(rows, mUnits, nil)
+	from := 0
+	for _, layer := range layers {
+		if layer.Weight > 0 {
+			x := data.View(0, from, rows, layer.Dim).(*mat64.Dense)
+			w := codebook.View(0, from, mUnits, layer.Dim).(*mat64.Dense)
+			layerDists, err := distMx(layer.Metric, x, w, sqNorms(w))
+			if err != nil {
+				return nil, err
+			}
+			for i := 0; i < rows; i++ {
+				row := layerDists.RawRowView(i)
+				if layer.Metric == "euclidean" {
+					for j := range row {
+						row[j] = math.Sqrt(row[j])
+					}
+				}
+				floats.AddScaled(dists.RawRowView(i), layer.Weight/layer.Scale, row)
+			}
+		}
+		from += layer.Dim
+	}
+	return dists, nil
+}
+
+// newLayers returns codebook layers of the map with the given configuration trained on data
+// matrix. Layer weights are normalized to sum up to 1, layers without distance metric are
+// assigned the map distance metric and layers without scale are assigned the mean distance
+// between data samples in the layer: see layerScale. If the configuration contains no layers,
+// a single layer which contains all data features is returned.
+// It returns error if the layer dimensions don't add up to data dimension or if any of the
+// layer scales could not be computed.
+func newLayers(c *Config, data *mat64.Dense) ([]Layer, error) {
+	rows, cols := data.Dims()
+	if len(c.Layers) == 0 {
+		return []Layer{{Dim: cols, Weight: 1.0, Metric: c.Metric, Scale: 1.0}}, nil
+	}
+	layers := make([]Layer, len(c.Layers))
+	dim, weight := 0, 0.0
+	for _, layer := range c.Layers {
+		dim += layer.Dim
+		weight += layer.Weight
+	}
+	if dim != cols {
+		return nil, fmt.Errorf("Layer dimension mismatch. Expected: %d, got: %d\n", cols, dim)
+	}
+	from := 0
+	for i, layer := range c.Layers {
+		layers[i] = Layer{Dim: layer.Dim, Weight: layer.Weight / weight, Metric: layer.Metric, Scale: layer.Scale}
+		if layers[i].Metric == "" {
+			layers[i].Metric = c.Metric
+		}
+		if layers[i].Scale == 0 {
+			x := data.View(0, from, rows, layer.Dim).(*mat64.Dense)
+			scale, err := layerScale(layers[i].Metric, x)
+			if err != nil {
+				return nil, err
+			}
+			layers[i].Scale = scale
+		}
+		from += layer.Dim
+	}
+	return layers, nil
+}
+
+// layerScale returns the mean distance between data samples stored in rows of data matrix
+// measured using the requested metric. Samples with missing values are ignored and if there
+// are more than 1000 remaining samples, only 1000 evenly spaced samples are used.
+// It returns 1 if there are less than 2 samples or if all the distances are zero.
+// It returns error if the requested metric is not supported.
+func layerScale(metric string, data *mat64.Dense) (float64, error) {
+	rows, cols := data.Dims()
+	complete := []int{}
+	for i := 0; i < rows; i++ {
+		if !hasMissing(data.RawRowView(i)) {
+			complete = append(complete, i)
+		}
+	}
+	if len(complete) < 2 {
+		return 1.0, nil
+	}
+	// pick evenly spaced data samples
+	size := len(complete)
+	if size > layerScaleSamples {
+		size = layerScaleSamples
+	}
+	samples := mat64.NewDense(size, cols, nil)
+	for i := 0; i < size; i++ {
+		samples.SetRow(i, data.RawRowView(complete[i*len(complete)/size]))
+	}
+	sum, count := 0.0, 0
+	err := blockDists(metric, samples, samples, sqNorms(samples), func(from int, blockDists *mat64.Dense) {
+		block, _ := blockDists.Dims()
+		for i := 0; i < block; i++ {
+			for _, dist := range blockDists.RawRowView(i)[from+i+1:] {
+				if metric == "euclidean" {
+					dist = math.Sqrt(dist)
+				}
+				sum += dist
+				count++
+			}
+		}
+	})
+	if err != nil {
+		return 0.0, err
+	}
+	if sum == 0 {
+		return 1.0, nil
+	}
+	return sum / float64(count), nil
+}
+
+// validateLayers validates codebook layers.
+// It returns error if any of the layers has non-positive dimension, negative weight or scale
+// or unsupported distance metric or if the weights of all layers are zero.
+func validateLayers(layers []Layer) error {
+	if len(layers) == 0 {
+		return nil
+	}
+	weight := 0.0
+	for _, layer := range layers {
+		if layer.Dim <= 0 {
+			return fmt.Errorf("Invalid layer dimension: %d\n", layer.Dim)
+		}
+		if layer.Weight < 0 {
+			return fmt.Errorf("Invalid layer weight: %f\n", layer.Weight)
+		}
+		if layer.Scale < 0 {
+			return fmt.Errorf("Invalid layer scale: %f\n", layer.Scale)
+		}
+		if _, ok := metricFunc(layer.Metric); layer.Metric != "" && !ok {
+			return fmt.Errorf("Unsupported layer distance metric: %s\n", layer.Metric)
+		}
+		weight += layer.Weight
+	}
+	if weight == 0 {
+		return fmt.Errorf("Invalid layer weights: %v\n", layers)
+	}
+	return nil
+}
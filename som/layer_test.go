@@ -0,0 +1,345 @@
+package som
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+// newLayerMap returns 1D map with the supplied codebook and codebook layers
+// created with a single zero data sample, so the layer scales default to 1
+func newLayerMap(layers []Layer, codebook *mat64.Dense) (*Map, error) {
+	_, cols := codebook.Dims()
+	return newTrainedLayerMap(layers, codebook, mat64.NewDense(1, cols, nil))
+}
+
+// newTrainedLayerMap returns 1D map with the supplied codebook and codebook layers
+// created with the supplied training data
+func newTrainedLayerMap(layers []Layer, codebook, data *mat64.Dense) (*Map, error) {
+	mUnits, _ := codebook.Dims()
+	c := &Config{
+		Dims:   []int{mUnits},
+		Grid:   "planar",
+		UShape: "rectangle",
+		InitFunc: func(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
+			return codebook, nil
+		},
+		RDecay:   "lin",
+		NeighbFn: "gaussian",
+		LDecay:   "lin",
+		Layers:   layers,
+	}
+	return NewMap(c, data)
+}
+
+func TestNewLayers(t *testing.T) {
+	assert := assert.New(t)
+
+	codebook := mat64.NewDense(2, 3, []float64{
+		0, 0, 1,
+		1, 1, 0,
+	})
+	m, err := newLayerMap(nil, codebook)
+	assert.NoError(err)
+	assert.Equal([]Layer{{Dim: 3, Weight: 1, Metric: "euclidean", Scale: 1}}, m.Layers())
+
+	m, err = newLayerMap([]Layer{{Dim: 2, Weight: 3}, {Dim: 1, Weight: 1, Metric: "manhattan"}}, codebook)
+	assert.NoError(err)
+	assert.Equal([]Layer{
+		{Dim: 2, Weight: 0.75, Metric: "euclidean", Scale: 1},
+		{Dim: 1, Weight: 0.25, Metric: "manhattan", Scale: 1},
+	}, m.Layers())
+
+	x, err := m.Layer(0)
+	assert.NoError(err)
+	assert.Equal([]float64{0, 0, 1, 1}, x.RawMatrix().Data)
+	y, err := m.Layer(1)
+	assert.NoError(err)
+	assert.Equal([]float64{1, 0}, y.RawMatrix().Data)
+	for _, layer := range []int{-1, 2} {
+		l, err := m.Layer(layer)
+		assert.Nil(l)
+		assert.Error(err)
+	}
+
+	// layer dimensions must match data dimension
+	m, err = newLayerMap([]Layer{{Dim: 2, Weight: 1}, {Dim: 2, Weight: 1}}, codebook)
+	assert.Nil(m)
+	assert.EqualError(err, "Layer dimension mismatch. Expected: 3, got: 4\n")
+
+	// layer scales must not be negative
+	m, err = newLayerMap([]Layer{{Dim: 2, Weight: 1}, {Dim: 1, Weight: 1, Scale: -1}}, codebook)
+	assert.Nil(m)
+	assert.Error(err)
+}
+
+func TestLayerScales(t *testing.T) {
+	assert := assert.New(t)
+
+	data := mat64.NewDense(4, 3, []float64{
+		0, 0, 1,
+		3, 4, 0,
+		0, 0, math.NaN(),
+		3, 4, 1,
+	})
+	same := mat64.NewDense(3, 3, []float64{
+		0, 1, 1,
+		3, 1, 1,
+		0, 1, 1,
+	})
+	testCases := []struct {
+		data   *mat64.Dense
+		layers []Layer
+		scales []float64
+	}{
+		// mean of pairwise distances between samples without missing values
+		{data, []Layer{{Dim: 2, Weight: 1}, {Dim: 1, Weight: 1}}, []float64{10.0 / 3.0, 2.0 / 3.0}},
+		{data, []Layer{{Dim: 2, Weight: 1, Metric: "sqeuclidean"}, {Dim: 1, Weight: 1, Metric: "manhattan"}}, []float64{50.0 / 3.0, 2.0 / 3.0}},
+		// configured scales are kept
+		{data, []Layer{{Dim: 2, Weight: 1, Scale: 2}, {Dim: 1, Weight: 1}}, []float64{2, 2.0 / 3.0}},
+		// zero distances give unit scale
+		{same, []Layer{{Dim: 1, Weight: 1}, {Dim: 2, Weight: 1}}, []float64{2, 1}},
+	}
+	codebook := mat64.NewDense(2, 3, nil)
+	for _, tc := range testCases {
+		m, err := newTrainedLayerMap(tc.layers, codebook, tc.data)
+		assert.NoError(err)
+		for i, layer := range m.Layers() {
+			assert.InDelta(tc.scales[i], layer.Scale, 1e-9)
+		}
+	}
+}
+
+func TestLayerInfluence(t *testing.T) {
+	assert := assert.New(t)
+
+	// x layer votes for unit 0 and y layer votes for unit 1 with equal relative strength
+	codebook := mat64.NewDense(2, 2, []float64{
+		0, 1,
+		1, 0,
+	})
+	data := mat64.NewDense(4, 2, []float64{
+		0, 1,
+		1, 0,
+		0, 0,
+		1, 1,
+	})
+	sample := mat64.NewDense(1, 2, []float64{0.3, 0.2})
+	layers := []Layer{{Dim: 1, Weight: 0.5}, {Dim: 1, Weight: 0.5}}
+	m, err := newTrainedLayerMap(layers, codebook, data)
+	assert.NoError(err)
+	bmus, dists, err := m.BMUsFor(sample)
+	assert.NoError(err)
+	assert.Equal([]int{1}, bmus)
+	// (0.5*0.7 + 0.5*0.2) / (2/3)
+	assert.InDelta(0.675, dists[0], 1e-9)
+
+	// scaling x layer by 100 must not change the result
+	for _, mx := range []*mat64.Dense{codebook, data, sample} {
+		rows, _ := mx.Dims()
+		for i := 0; i < rows; i++ {
+			mx.Set(i, 0, 100*mx.At(i, 0))
+		}
+	}
+	m, err = newTrainedLayerMap(layers, codebook, data)
+	assert.NoError(err)
+	assert.InDelta(200.0/3.0, m.Layers()[0].Scale, 1e-9)
+	scaledBMUs, scaledDists, err := m.BMUsFor(sample)
+	assert.NoError(err)
+	assert.Equal(bmus, scaledBMUs)
+	assert.InDelta(dists[0], scaledDists[0], 1e-9)
+}
+
+func TestLayerBMUs(t *testing.T) {
+	assert := assert.New(t)
+
+	codebook := mat64.NewDense(2, 2, []float64{
+		0, 1,
+		1, 0,
+	})
+	data := mat64.NewDense(2, 2, []float64{
+		0.2, 0,
+		0.4, 0.9,
+	})
+	testCases := []struct {
+		wx, wy float64
+		bmus   []int
+		dists  []float64
+	}{
+		{1, 0, []int{0, 0}, []float64{0.2, 0.4}},
+		{0, 1, []int{1, 0}, []float64{0, 0.1}},
+		{0.9, 0.1, []int{0, 0}, []float64{0.28, 0.37}},
+		{1, 1, []int{1, 0}, []float64{0.4, 0.25}},
+	}
+	for _, tc := range testCases {
+		m, err := newLayerMap([]Layer{{Dim: 1, Weight: tc.wx}, {Dim: 1, Weight: tc.wy}}, codebook)
+		assert.NoError(err)
+		bmus, dists, err := m.BMUsFor(data)
+		assert.NoError(err)
+		assert.Equal(tc.bmus, bmus)
+		for i := range dists {
+			assert.InDelta(tc.dists[i], dists[i], 1e-9)
+		}
+		bmu, dist, err := m.BMU(data.RowView(0))
+		assert.NoError(err)
+		assert.Equal(tc.bmus[0], bmu)
+		assert.InDelta(tc.dists[0], dist, 1e-9)
+		units, dists, err := m.KBMUs(data.RowView(0), 2)
+		assert.NoError(err)
+		assert.Equal([]int{tc.bmus[0], 1 - tc.bmus[0]}, units)
+		assert.InDelta(tc.dists[0], dists[0], 1e-9)
+	}
+
+	m, err := newLayerMap([]Layer{{Dim: 1, Weight: 1}, {Dim: 1, Weight: 1}}, codebook)
+	assert.NoError(err)
+	x := mat64.NewDense(2, 1, []float64{0.2, 0.6})
+	bmus, dists, err := m.LayerBMUs(x, 0)
+	assert.NoError(err)
+	assert.Equal([]int{0, 1}, bmus)
+	assert.InDelta(0.2, dists[0], 1e-9)
+	assert.InDelta(0.4, dists[1], 1e-9)
+	y, err := m.PredictLayer(x, 0, 1)
+	assert.NoError(err)
+	assert.Equal([]float64{1, 0}, y.RawMatrix().Data)
+
+	errCases := []struct {
+		data     *mat64.Dense
+		from, to int
+	}{
+		{nil, 0, 1},
+		{data, 0, 1},
+		{x, -1, 1},
+		{x, 0, 2},
+	}
+	for _, tc := range errCases {
+		y, err := m.PredictLayer(tc.data, tc.from, tc.to)
+		assert.Nil(y)
+		assert.Error(err)
+	}
+	bmus, dists, err = m.BMUsFor(x)
+	assert.Nil(bmus)
+	assert.Nil(dists)
+	assert.Error(err)
+}
+
+func TestLayerQuality(t *testing.T) {
+	assert := assert.New(t)
+
+	// whole vector BMU of the sample is unit 0 and its second BMU is unit 1,
+	// whereas its layer distance BMU is unit 0 and its second BMU is unit 2
+	codebook := mat64.NewDense(3, 2, []float64{
+		0, 0,
+		5, 5,
+		1, 10,
+	})
+	data := mat64.NewDense(1, 2, []float64{1, 0})
+	m, err := newLayerMap([]Layer{{Dim: 1, Weight: 0.9}, {Dim: 1, Weight: 0.1}}, codebook)
+	assert.NoError(err)
+	bmus, dists, err := m.BMUsFor(data)
+	assert.NoError(err)
+	assert.Equal([]int{0}, bmus)
+	assert.InDelta(0.9, dists[0], 1e-9)
+
+	qe, err := m.QuantError(data)
+	assert.NoError(err)
+	assert.InDelta(dists[0], qe, 1e-9)
+	// units 0 and 2 are not neighbours
+	te, err := m.TopoError(data)
+	assert.NoError(err)
+	assert.Equal(1.0, te)
+	// quantization error plus path 0 -> 1 -> 2 of lengths 5 and 4.1
+	ce, err := m.CombinedError(data)
+	assert.NoError(err)
+	assert.InDelta(0.9+5+4.1, ce, 1e-9)
+	// distortion weighs squared layer distances: 0.9, 4.1 and 1
+	distortion, err := m.Distortion(data, 1)
+	assert.NoError(err)
+	neighbFn := Neighb[m.config.NeighbFn]
+	expected := neighbFn(0, 1)*0.81 + neighbFn(1, 1)*4.1*4.1 + neighbFn(2, 1)*1
+	assert.InDelta(expected, distortion, 1e-9)
+
+	// predictor classifies data samples by the units they are mapped to
+	train := mat64.NewDense(2, 2, []float64{
+		1, 0,
+		5, 5,
+	})
+	p, err := NewPredictor(m, train, []string{"a", "b"}, 1, false)
+	assert.NoError(err)
+	classes, err := p.Classify(train)
+	assert.NoError(err)
+	assert.Equal([]string{"a", "b"}, classes)
+	// the sample is closer to unit 1 on whole vectors, but closer to unit 0 in layer distances
+	classes, err = p.Classify(mat64.NewDense(1, 2, []float64{1.5, 9}))
+	assert.NoError(err)
+	assert.Equal([]string{"a"}, classes)
+}
+
+func TestTrainLayers(t *testing.T) {
+	assert := assert.New(t)
+
+	// two clusters of samples whose targets are one-hot encoded classes
+	x := mat64.NewDense(6, 2, []float64{
+		0.0, 0.1,
+		0.1, 0.0,
+		0.1, 0.1,
+		0.9, 1.0,
+		1.0, 0.9,
+		1.0, 1.0,
+	})
+	labels := []string{"a", "a", "a", "b", "b", "b"}
+	y, classes, err := OneHot(labels)
+	assert.NoError(err)
+	assert.Equal([]string{"a", "b"}, classes)
+	data := new(mat64.Dense)
+	data.Augment(x, y)
+
+	for _, train := range []string{"seq", "batch"} {
+		c := &Config{
+			Dims:     []int{4},
+			Grid:     "planar",
+			UShape:   "rectangle",
+			InitFunc: LinInit,
+			RDecay:   "lin",
+			NeighbFn: "gaussian",
+			LDecay:   "lin",
+			Layers:   []Layer{{Dim: 2, Weight: 0.5}, {Dim: 2, Weight: 0.5}},
+		}
+		m, err := NewMap(c, data)
+		assert.NoError(err)
+		if train == "seq" {
+			assert.NoError(m.TrainSeq(data, 300))
+		} else {
+			assert.NoError(m.TrainBatch(data, 20))
+		}
+		predicted, err := m.PredictLayer(x, 0, 1)
+		assert.NoError(err)
+		for i, label := range labels {
+			class := 0
+			if predicted.At(i, 1) > predicted.At(i, 0) {
+				class = 1
+			}
+			assert.Equal(label, classes[class])
+		}
+	}
+}
+
+func TestOneHot(t *testing.T) {
+	assert := assert.New(t)
+
+	encoded, classes, err := OneHot([]string{"b", "a", "c", "a"})
+	assert.NoError(err)
+	assert.Equal([]string{"a", "b", "c"}, classes)
+	assert.Equal([]float64{
+		0, 1, 0,
+		1, 0, 0,
+		0, 0, 1,
+		1, 0, 0,
+	}, encoded.RawMatrix().Data)
+
+	encoded, classes, err = OneHot(nil)
+	assert.Nil(encoded)
+	assert.Nil(classes)
+	assert.Error(err)
+}
@@ -0,0 +1,128 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+const (
+	// paretoPercentile is the percentile of the distances between data samples used as Pareto radius
+	paretoPercentile = 0.18
+	// paretoSamples is the maximum number of data samples used to compute Pareto radius
+	paretoSamples = 1000
+)
+
+// ParetoRadius returns Pareto radius of data samples stored in rows of data matrix measured
+// using the requested distance metric. Pareto radius is the radius of hyperspheres around
+// data samples which maximizes the information content of the Pareto density estimation.
+// It is approximated by the 18th percentile of the distances between data samples.
+// If data contains more than 1000 samples, only 1000 evenly spaced samples are used.
+// See Ultsch A: Pareto Density Estimation: A Density Estimation for Knowledge Discovery (2003).
+// It returns error if data is nil, if it contains less than 2 samples or if the requested
+// metric is not supported.
+func ParetoRadius(metric string, data *mat64.Dense) (float64, error) {
+	radius, err := paretoRadius(metric, data)
+	if err != nil {
+		return 0.0, err
+	}
+	if metric == "euclidean" {
+		radius = math.Sqrt(radius)
+	}
+	return radius, nil
+}
+
+// paretoRadius returns Pareto radius of data samples computed using distMx, so euclidean
+// radius is squared.
+func paretoRadius(metric string, data *mat64.Dense) (float64, error) {
+	if err := validateDims(data, data); err != nil {
+		return 0.0, err
+	}
+	rows, cols := data.Dims()
+	if rows < 2 {
+		return 0.0, fmt.Errorf("Insufficient number of data samples: %d\n", rows)
+	}
+	// pick evenly spaced data samples
+	samples := data
+	if rows > paretoSamples {
+		samples = mat64.NewDense(paretoSamples, cols, nil)
+		for i := 0; i < paretoSamples; i++ {
+			samples.SetRow(i, data.RawRowView(i*rows/paretoSamples))
+		}
+		rows = paretoSamples
+	}
+	dists := make([]float64, 0, rows*(rows-1)/2)
+	err := blockDists(metric, samples, samples, sqNorms(samples), func(from int, blockDists *mat64.Dense) {
+		size, _ := blockDists.Dims()
+		for i := 0; i < size; i++ {
+			dists = append(dists, blockDists.RawRowView(i)[from+i+1:]...)
+		}
+	})
+	if err != nil {
+		return 0.0, err
+	}
+	sort.Float64s(dists)
+	return dists[int(paretoPercentile*float64(len(dists)-1))], nil
+}
+
+// PMatrix returns a slice which contains P-matrix values of all SOM units. P-matrix value of
+// a SOM unit is the number of data samples stored in rows of data matrix which are within
+// Pareto radius of its codebook vector: see ParetoRadius. Distances are measured using the
+// map distance metric. P-matrix estimates data density at SOM units.
+// See Ultsch A: Maps for the Visualization of high-dimensional Data Spaces (2003).
+// It returns error if data is nil, if it contains less than 2 samples or if its dimension
+// does not match the codebook dimension.
+func (m Map) PMatrix(data *mat64.Dense) ([]float64, error) {
+	if err := validateDims(data, m.codebook); err != nil {
+		return nil, err
+	}
+	radius, err := paretoRadius(m.config.Metric, data)
+	if err != nil {
+		return nil, err
+	}
+	pmatrix := make([]float64, m.grid.Units())
+	err = blockDists(m.config.Metric, data, m.codebook, sqNorms(m.codebook), func(from int, dists *mat64.Dense) {
+		size, _ := dists.Dims()
+		for i := 0; i < size; i++ {
+			for unit, dist := range dists.RawRowView(i) {
+				if dist <= radius {
+					pmatrix[unit]++
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pmatrix, nil
+}
+
+// UStarMatrix returns a slice which contains U*-matrix values of all SOM units.
+// U*-matrix combines U-matrix computed with the requested statistic and P-matrix computed
+// from data: U-matrix values are scaled down in the areas of high data density, so that
+// the cluster borders within dense areas become visible. U-matrix value of a unit is
+// multiplied by (P - mean(P))/(mean(P) - max(P)) + 1 where P is its P-matrix value.
+// See Ultsch A: U*-Matrix: a Tool to visualize Clusters in high dimensional Data (2003).
+// It returns error if the U-matrix or the P-matrix could not be computed.
+func (m Map) UStarMatrix(data *mat64.Dense, stat string) ([]float64, error) {
+	umatrix, err := m.UMatrix(stat)
+	if err != nil {
+		return nil, err
+	}
+	pmatrix, err := m.PMatrix(data)
+	if err != nil {
+		return nil, err
+	}
+	pMean, pMax := mean(pmatrix), floats.Max(pmatrix)
+	// uniform density does not change U-matrix
+	if pMean == pMax {
+		return umatrix, nil
+	}
+	for i, p := range pmatrix {
+		umatrix[i] *= (p-pMean)/(pMean-pMax) + 1
+	}
+	return umatrix, nil
+}
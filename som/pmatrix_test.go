@@ -0,0 +1,125 @@
+package som
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParetoRadius(t *testing.T) {
+	assert := assert.New(t)
+
+	// 12 samples on a line: 66 distances, 18th percentile is the 12th smallest
+	data := mat64.NewDense(12, 1, nil)
+	for i := 0; i < 12; i++ {
+		data.Set(i, 0, float64(i))
+	}
+	for _, metric := range []string{"euclidean", "manhattan", "chebyshev"} {
+		radius, err := ParetoRadius(metric, data)
+		assert.NoError(err)
+		assert.InDelta(2.0, radius, 1e-9, metric)
+	}
+	radius, err := ParetoRadius("sqeuclidean", data)
+	assert.NoError(err)
+	assert.InDelta(4.0, radius, 1e-9)
+
+	// large data sets are subsampled
+	big := mat64.NewDense(2*paretoSamples, 1, nil)
+	for i := 0; i < 2*paretoSamples; i++ {
+		big.Set(i, 0, float64(i))
+	}
+	radius, err = ParetoRadius("euclidean", big)
+	assert.NoError(err)
+	assert.True(radius > 0 && radius < 2*paretoSamples)
+	assert.Equal(0.0, math.Mod(radius, 2))
+
+	errCases := []struct {
+		metric string
+		data   *mat64.Dense
+	}{
+		{"euclidean", nil},
+		{"euclidean", mat64.NewDense(1, 2, nil)},
+		{"foobar", data},
+	}
+	for _, tc := range errCases {
+		radius, err := ParetoRadius(tc.metric, tc.data)
+		assert.Equal(0.0, radius)
+		assert.Error(err)
+	}
+}
+
+func TestPMatrix(t *testing.T) {
+	assert := assert.New(t)
+
+	// dense cluster around 0 and sparse samples around 10
+	data := mat64.NewDense(12, 1, []float64{
+		0.0, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7,
+		10, 13, 16, 19,
+	})
+	codebook := mat64.NewDense(4, 1, []float64{0.3, 5, 10, 16})
+	m, err := newUMatrixMap("planar", "rectangle", []int{4}, codebook)
+	assert.NoError(err)
+	radius, err := ParetoRadius("euclidean", data)
+	assert.NoError(err)
+	pmatrix, err := m.PMatrix(data)
+	assert.NoError(err)
+	// count samples within radius
+	for unit, p := range pmatrix {
+		count := 0.0
+		for i := 0; i < 12; i++ {
+			if math.Abs(data.At(i, 0)-codebook.At(unit, 0)) <= radius {
+				count++
+			}
+		}
+		assert.Equal(count, p)
+	}
+	assert.Equal(5.0, pmatrix[0])
+	assert.Equal(0.0, pmatrix[1])
+
+	pmatrix, err = m.PMatrix(mat64.NewDense(2, 3, nil))
+	assert.Nil(pmatrix)
+	assert.Error(err)
+	pmatrix, err = m.PMatrix(nil)
+	assert.Nil(pmatrix)
+	assert.Error(err)
+}
+
+func TestUStarMatrix(t *testing.T) {
+	assert := assert.New(t)
+
+	data := mat64.NewDense(12, 1, []float64{
+		0.0, 0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7,
+		10, 13, 16, 19,
+	})
+	codebook := mat64.NewDense(4, 1, []float64{0.3, 5, 10, 16})
+	m, err := newUMatrixMap("planar", "rectangle", []int{4}, codebook)
+	assert.NoError(err)
+	umatrix, err := m.UMatrix("mean")
+	assert.NoError(err)
+	pmatrix, err := m.PMatrix(data)
+	assert.NoError(err)
+	ustar, err := m.UStarMatrix(data, "mean")
+	assert.NoError(err)
+	pMean, pMax := mean(pmatrix), floats.Max(pmatrix)
+	for i := range ustar {
+		assert.InDelta(umatrix[i]*((pmatrix[i]-pMean)/(pMean-pMax)+1), ustar[i], 1e-9)
+	}
+	// the densest unit is scaled down to zero
+	assert.Equal(0.0, ustar[0])
+
+	// uniform density: 3 samples at every codebook vector
+	data = mat64.NewDense(12, 1, []float64{0.3, 0.3, 0.3, 5, 5, 5, 10, 10, 10, 16, 16, 16})
+	ustar, err = m.UStarMatrix(data, "mean")
+	assert.NoError(err)
+	assert.Equal(umatrix, ustar)
+
+	ustar, err = m.UStarMatrix(data, "foobar")
+	assert.Nil(ustar)
+	assert.Error(err)
+	ustar, err = m.UStarMatrix(nil, "mean")
+	assert.Nil(ustar)
+	assert.Error(err)
+}
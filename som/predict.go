@@ -0,0 +1,181 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// Predictor is a SOM based classifier. SOM units are labeled with the label frequencies of
+// labeled training data samples mapped to them: see LabelFreqs. Data samples are classified
+// by the labels of the k labeled units closest to them, optionally weighting the votes
+// of the units by inverse of their distance from the classified data samples.
+type Predictor struct {
+	// metric is the distance metric used to find the units closest to data samples
+	metric string
+	// layers contains codebook layers of the map: distances of multi-layer maps are
+	// weighted sums of layer distances as in BMU search
+	layers []Layer
+	// codebook contains codebook vectors of the labeled SOM units
+	codebook *mat64.Dense
+	// cbNorms contains squared Euclidean norms of the codebook vectors
+	cbNorms []float64
+	// freqs contains label frequencies of the labeled SOM units
+	freqs []map[string]float64
+	// classes contains lexically ordered class labels
+	classes []string
+	// index maps class labels to their positions in classes
+	index map[string]int
+	// k is the number of the closest labeled units which classify data samples
+	k int
+	// weighted enables inverse distance weighting of the votes of the closest units
+	weighted bool
+}
+
+// NewPredictor creates new Predictor which labels units of SOM m using the data samples stored
+// in rows of data matrix labeled with labels. Data samples are classified using k closest
+// labeled units whose votes are weighted by inverse of their distance if weighted is true.
+// With k equal to 1 data samples are classified by the labels of their closest labeled units.
+// It returns error if m is nil, if data is nil, if its dimension does not match the codebook
+// dimension, if the number of labels does not match the number of data samples, if any label
+// is empty or if k is not positive or it exceeds the number of labeled units.
+func NewPredictor(m *Map, data *mat64.Dense, labels []string, k int, weighted bool) (*Predictor, error) {
+	if m == nil {
+		return nil, fmt.Errorf("Invalid map: %v\n", m)
+	}
+	unitFreqs, err := m.LabelFreqs(data, labels)
+	if err != nil {
+		return nil, err
+	}
+	_, cols := m.codebook.Dims()
+	cbData := []float64{}
+	freqs := []map[string]float64{}
+	for unit, unitFreq := range unitFreqs {
+		if len(unitFreq) > 0 {
+			cbData = append(cbData, m.codebook.RawRowView(unit)...)
+			freqs = append(freqs, unitFreq)
+		}
+	}
+	if k <= 0 || k > len(freqs) {
+		return nil, fmt.Errorf("Invalid number of nearest units: %d\n", k)
+	}
+	codebook := mat64.NewDense(len(freqs), cols, cbData)
+	classes := uniqueLabels(labels)
+	index := make(map[string]int)
+	for i, class := range classes {
+		index[class] = i
+	}
+	return &Predictor{
+		metric:   m.config.Metric,
+		layers:   m.layers,
+		codebook: codebook,
+		cbNorms:  sqNorms(codebook),
+		freqs:    freqs,
+		classes:  classes,
+		index:    index,
+		k:        k,
+		weighted: weighted,
+	}, nil
+}
+
+// Classes returns lexically ordered class labels known to the predictor
+func (p *Predictor) Classes() []string {
+	return p.classes
+}
+
+// Probabilities returns a matrix which contains class probabilities of the data samples stored
+// in rows of data matrix. Rows of the returned matrix correspond to the data samples and its
+// columns to the classes returned by Classes. Class probability of a data sample is the
+// (weighted) average of the class label frequencies of the closest labeled units.
+// Distances between data samples and labeled units are measured in the same way as in BMU search,
+// so training data samples are classified by the units they were mapped to.
+// It returns error if data is nil or if its dimension does not match the codebook dimension.
+func (p *Predictor) Probabilities(data *mat64.Dense) (*mat64.Dense, error) {
+	if err := validateDims(data, p.codebook); err != nil {
+		return nil, err
+	}
+	rows, _ := data.Dims()
+	probs := mat64.NewDense(rows, len(p.classes), nil)
+	err := forBlocks(data, p.dists, func(from int, blockDists *mat64.Dense) {
+		size, _ := blockDists.Dims()
+		for i := 0; i < size; i++ {
+			p.vote(blockDists.RawRowView(i), probs.RawRowView(from+i))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return probs, nil
+}
+
+// Classify returns class labels of the data samples stored in rows of data matrix.
+// Every data sample is labeled with the most probable class: see Probabilities.
+// Ties are broken in favour of the class which comes first in lexical order.
+// It returns error if data is nil or if its dimension does not match the codebook dimension.
+func (p *Predictor) Classify(data *mat64.Dense) ([]string, error) {
+	probs, err := p.Probabilities(data)
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := probs.Dims()
+	labels := make([]string, rows)
+	for i := range labels {
+		labels[i] = p.classes[floats.MaxIdx(probs.RawRowView(i))]
+	}
+	return labels, nil
+}
+
+// dists returns a matrix of distances between rows of data matrix and labeled codebook vectors.
+// Euclidean distances are not squared.
+// It returns error if the distance metric is not supported.
+func (p *Predictor) dists(data *mat64.Dense) (*mat64.Dense, error) {
+	if len(p.layers) >= 2 {
+		return layerDistMx(p.layers, data, p.codebook)
+	}
+	dists, err := distMx(p.metric, data, p.codebook, p.cbNorms)
+	if err != nil {
+		return nil, err
+	}
+	if p.metric == "euclidean" {
+		dists.Apply(func(i, j int, x float64) float64 {
+			return math.Sqrt(x)
+		}, dists)
+	}
+	return dists, nil
+}
+
+// vote stores class probabilities of a data sample in probs given the distances of the data
+// sample from all labeled units in dists. If inverse distance weighting is enabled and
+// the data sample matches any of the closest units exactly, only the matching units vote.
+func (p *Predictor) vote(dists, probs []float64) {
+	units := make([]int, len(dists))
+	for i := range units {
+		units[i] = i
+	}
+	sort.Stable(byDist{units: units, dists: dists})
+	units = units[:p.k]
+	weights := make([]float64, p.k)
+	for i := range weights {
+		weights[i] = 1.0
+		if p.weighted {
+			weights[i] = 1.0 / dists[units[i]]
+		}
+	}
+	if p.weighted && dists[units[0]] == 0.0 {
+		for i, unit := range units {
+			weights[i] = 0.0
+			if dists[unit] == 0.0 {
+				weights[i] = 1.0
+			}
+		}
+	}
+	for i, unit := range units {
+		for label, freq := range p.freqs[unit] {
+			probs[p.index[label]] += weights[i] * freq
+		}
+	}
+	floats.Scale(1.0/floats.Sum(weights), probs)
+}
@@ -0,0 +1,105 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLabeledPredictor(k int, weighted bool) (*Predictor, error) {
+	codebook := mat64.NewDense(4, 1, []float64{0, 1, 3, 6})
+	m, err := newUMatrixMap("planar", "rectangle", []int{4}, codebook)
+	if err != nil {
+		return nil, err
+	}
+	data := mat64.NewDense(6, 1, []float64{0.1, 0.2, -0.1, 2.95, 3.1, 6.2})
+	labels := []string{"a", "a", "b", "b", "c", "c"}
+	return NewPredictor(m, data, labels, k, weighted)
+}
+
+func TestNewPredictor(t *testing.T) {
+	assert := assert.New(t)
+
+	p, err := newLabeledPredictor(1, false)
+	assert.NoError(err)
+	assert.Equal([]string{"a", "b", "c"}, p.Classes())
+	// unit 1 is not labeled
+	assert.Equal(3, len(p.freqs))
+	assert.Equal([]float64{0, 3, 6}, p.codebook.RawMatrix().Data)
+
+	// 3 units are labeled
+	for _, k := range []int{0, -1, 4} {
+		p, err := newLabeledPredictor(k, false)
+		assert.Nil(p)
+		assert.Error(err)
+	}
+	// invalid map
+	data := mat64.NewDense(1, 1, []float64{0.1})
+	p, err = NewPredictor(nil, data, []string{"a"}, 1, false)
+	assert.Nil(p)
+	assert.Error(err)
+	// invalid labels
+	codebook := mat64.NewDense(2, 1, []float64{0, 1})
+	m, err := newUMatrixMap("planar", "rectangle", []int{2}, codebook)
+	assert.NoError(err)
+	p, err = NewPredictor(m, data, []string{"a", "b"}, 1, false)
+	assert.Nil(p)
+	assert.Error(err)
+}
+
+func TestPredictorClassify(t *testing.T) {
+	assert := assert.New(t)
+
+	data := mat64.NewDense(4, 1, []float64{0.9, 5.0, 3.0, 1.0})
+	// inverse distances of the first data sample from the two closest labeled units
+	w0, w2 := 1/0.9, 1/2.1
+	testCases := []struct {
+		k        int
+		weighted bool
+		probs    []float64
+		labels   []string
+	}{
+		{1, false, []float64{
+			2.0 / 3, 1.0 / 3, 0,
+			0, 0, 1,
+			0, 0.5, 0.5,
+			2.0 / 3, 1.0 / 3, 0}, []string{"a", "c", "b", "a"}},
+		{2, false, []float64{
+			1.0 / 3, 5.0 / 12, 0.25,
+			0, 0.25, 0.75,
+			1.0 / 3, 5.0 / 12, 0.25,
+			1.0 / 3, 5.0 / 12, 0.25}, []string{"b", "c", "b", "b"}},
+		{2, true, []float64{
+			2.0 / 3 * w0 / (w0 + w2), (w0/3 + w2/2) / (w0 + w2), w2 / 2 / (w0 + w2),
+			0, 1.0 / 6, 5.0 / 6,
+			0, 0.5, 0.5,
+			4.0 / 9, 7.0 / 18, 1.0 / 6}, []string{"a", "c", "b", "a"}},
+	}
+	for _, tc := range testCases {
+		p, err := newLabeledPredictor(tc.k, tc.weighted)
+		assert.NoError(err)
+		probs, err := p.Probabilities(data)
+		assert.NoError(err)
+		rows, cols := probs.Dims()
+		assert.Equal(4, rows)
+		assert.Equal(3, cols)
+		for i, prob := range probs.RawMatrix().Data {
+			assert.InDelta(tc.probs[i], prob, 1e-9)
+		}
+		labels, err := p.Classify(data)
+		assert.NoError(err)
+		assert.Equal(tc.labels, labels)
+	}
+
+	p, err := newLabeledPredictor(1, false)
+	assert.NoError(err)
+	for _, data := range []*mat64.Dense{nil, mat64.NewDense(1, 2, nil)} {
+		probs, err := p.Probabilities(data)
+		assert.Nil(probs)
+		assert.Error(err)
+		labels, err := p.Classify(data)
+		assert.Nil(labels)
+		assert.Error(err)
+	}
+}
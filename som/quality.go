@@ -0,0 +1,370 @@
+package som
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gonum/matrix/mat64"
+)
+
+// QuantError returns mean quantization error of the map for the data samples stored in rows
+// of data matrix. Quantization error of a data sample is its distance from its BMU measured
+// in the same way as in BMU search: see Layer.
+// It returns error if data is nil or if its dimension does not match the codebook dimension.
+func (m Map) QuantError(data *mat64.Dense) (float64, error) {
+	_, dists, err := m.BMUsFor(data)
+	if err != nil {
+		return 0.0, err
+	}
+	qe := 0.0
+	for _, dist := range dists {
+		qe += dist
+	}
+	return qe / float64(len(dists)), nil
+}
+
+// TopoError returns topographic error of the map for the data samples stored in rows of data
+// matrix. Topographic error is the share of data samples whose BMU and second BMU are not
+// immediate neighbours on the map grid: see Grid.Neighbors.
+// It returns error if data is nil or if its dimension does not match the codebook dimension.
+func (m Map) TopoError(data *mat64.Dense) (float64, error) {
+	errs := 0
+	err := m.unitBlocks(data, func(from int, dists *mat64.Dense) {
+		size, _ := dists.Dims()
+		for i := 0; i < size; i++ {
+			first, second := twoClosest(dists.RawRowView(i))
+			if m.grid.hops(first, second, false) != 1 {
+				errs++
+			}
+		}
+	})
+	if err != nil {
+		return 0.0, err
+	}
+	rows, _ := data.Dims()
+	return float64(errs) / float64(rows), nil
+}
+
+// Distortion returns mean distortion measure of the map for the data samples stored in rows
+// of data matrix. Distortion of a data sample is the sum of its squared distances from all
+// codebook vectors weighted by the map neighbourhood function of the grid distances between
+// the codebook vector units and the sample BMU. SOM training approximately minimizes distortion.
+// It returns error if data is nil, if its dimension does not match the codebook dimension
+// or if radius is not positive.
+func (m Map) Distortion(data *mat64.Dense, radius float64) (float64, error) {
+	if radius <= 0 {
+		return 0.0, fmt.Errorf("Invalid SOM unit radius: %f\n", radius)
+	}
+	neighbFn := Neighb[m.config.NeighbFn]
+	// euclidean and sqeuclidean distance matrices of single layer maps already contain
+	// squared distances
+	squared := len(m.layers) < 2 && (m.config.Metric == "euclidean" || m.config.Metric == "sqeuclidean")
+	distortion := 0.0
+	err := m.unitBlocks(data, func(from int, dists *mat64.Dense) {
+		size, _ := dists.Dims()
+		for i := 0; i < size; i++ {
+			row := dists.RawRowView(i)
+			bmu, _ := twoClosest(row)
+			gridDist := m.grid.row(bmu)
+			for j, dist := range row {
+				if !squared {
+					dist *= dist
+				}
+				distortion += neighbFn(gridDist[j], radius) * dist
+			}
+		}
+	})
+	if err != nil {
+		return 0.0, err
+	}
+	rows, _ := data.Dims()
+	return distortion / float64(rows), nil
+}
+
+// twoClosest returns indices of the smallest and the second smallest element of dists.
+// dists must contain at least two elements.
+func twoClosest(dists []float64) (int, int) {
+	first, second := 0, 1
+	if dists[second] < dists[first] {
+		first, second = second, first
+	}
+	for j := 2; j < len(dists); j++ {
+		switch {
+		case dists[j] < dists[first]:
+			first, second = j, first
+		case dists[j] < dists[second]:
+			second = j
+		}
+	}
+	return first, second
+}
+
+// Trustworthiness returns trustworthiness of the map projection of the data samples stored
+// in rows of data matrix. Data samples are projected onto the grid positions of their BMUs.
+// Trustworthiness penalizes data samples which are among k nearest neighbours of a sample
+// on the map grid, but not in the data space. It ranges from 0 to 1 with 1 being the best.
+// See Venna J, Kaski S: Neighborhood Preservation in Nonlinear Projection Methods (2001).
+// The computation ranks all data samples for every data sample, which takes O(N^2 log N) time
+// for N data samples, so pass a random subset of rows of large data sets instead.
+// It returns error if data is nil, if its dimension does not match the codebook dimension or
+// if k is not positive or 3*k >= 2*N-1 where N is the number of data samples.
+func (m Map) Trustworthiness(data *mat64.Dense, k int) (float64, error) {
+	trust, _, err := m.trustCont(data, k)
+	return trust, err
+}
+
+// Continuity returns continuity of the map projection of the data samples stored in rows
+// of data matrix. Data samples are projected onto the grid positions of their BMUs.
+// Continuity penalizes data samples which are among k nearest neighbours of a sample
+// in the data space, but not on the map grid. It ranges from 0 to 1 with 1 being the best.
+// See Venna J, Kaski S: Neighborhood Preservation in Nonlinear Projection Methods (2001).
+// The computation ranks all data samples for every data sample, which takes O(N^2 log N) time
+// for N data samples, so pass a random subset of rows of large data sets instead.
+// It returns error if data is nil, if its dimension does not match the codebook dimension or
+// if k is not positive or 3*k >= 2*N-1 where N is the number of data samples.
+func (m Map) Continuity(data *mat64.Dense, k int) (float64, error) {
+	_, cont, err := m.trustCont(data, k)
+	return cont, err
+}
+
+// trustCont computes both trustworthiness and continuity of the map projection of data.
+// Neighbour ranks are computed for every data sample in turn, so the computation needs
+// O(N^2 log N) time, but only O(N) memory besides a block of data distances.
+// Ties in grid distances, such as the distances between samples sharing BMU, are broken by
+// data space distances; remaining ties are broken by data sample indices.
+func (m Map) trustCont(data *mat64.Dense, k int) (float64, float64, error) {
+	bmus, _, err := m.BMUsFor(data)
+	if err != nil {
+		return 0.0, 0.0, err
+	}
+	rows := len(bmus)
+	if k <= 0 || 3*k >= 2*rows-1 {
+		return 0.0, 0.0, fmt.Errorf("Invalid number of neighbours: %d\n", k)
+	}
+	inRanks := make([]int, rows)
+	outRanks := make([]int, rows)
+	outDists := make([]float64, rows)
+	order := make([]int, rows-1)
+	trust, cont := 0.0, 0.0
+	err = blockDists(m.config.Metric, data, data, sqNorms(data), func(from int, dists *mat64.Dense) {
+		size, _ := dists.Dims()
+		for i := 0; i < size; i++ {
+			sample := from + i
+			inDists := dists.RawRowView(i)
+			gridDist := m.grid.row(bmus[sample])
+			for j := range outDists {
+				outDists[j] = gridDist[bmus[j]]
+			}
+			rank(inRanks, order, sample, inDists, nil)
+			rank(outRanks, order, sample, outDists, inDists)
+			for j := range inRanks {
+				if outRanks[j] <= k && inRanks[j] > k {
+					trust += float64(inRanks[j] - k)
+				}
+				if inRanks[j] <= k && outRanks[j] > k {
+					cont += float64(outRanks[j] - k)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return 0.0, 0.0, err
+	}
+	norm := 2.0 / float64(rows*k*(2*rows-3*k-1))
+	return 1 - norm*trust, 1 - norm*cont, nil
+}
+
+// TopoProduct returns topographic product of the map. Topographic product measures whether
+// the nearest neighbours of SOM units on the grid are the same as the nearest neighbours of
+// their codebook vectors. It is close to 0 if the map preserves neighbourhoods, negative if the
+// map is too small and positive if the map is too large for the codebook vector distribution.
+// See Bauer HU, Pawelzik KR: Quantifying the neighborhood preservation of self-organizing
+// feature maps (1992). Ties in distances are broken by distances in the other space.
+// It returns error if any two codebook vectors are identical.
+func (m Map) TopoProduct() (float64, error) {
+	mUnits, _ := m.codebook.Dims()
+	cbRanks := make([]int, mUnits)
+	gridRanks := make([]int, mUnits)
+	order := make([]int, mUnits-1)
+	cbOrder := make([]int, mUnits-1)
+	gridOrder := make([]int, mUnits-1)
+	product := 0.0
+	var prodErr error
+	err := blockDists(m.config.Metric, m.codebook, m.codebook, sqNorms(m.codebook), func(from int, dists *mat64.Dense) {
+		size, _ := dists.Dims()
+		for i := 0; i < size && prodErr == nil; i++ {
+			unit := from + i
+			cbDists := dists.RawRowView(i)
+			gridDist := m.grid.row(unit)
+			rank(cbRanks, order, unit, cbDists, gridDist)
+			copy(cbOrder, order)
+			rank(gridRanks, order, unit, gridDist, cbDists)
+			copy(gridOrder, order)
+			logSum := 0.0
+			for k := range order {
+				g, c := gridOrder[k], cbOrder[k]
+				if cbDists[g] == 0 || cbDists[c] == 0 {
+					prodErr = fmt.Errorf("Identical codebook vectors: %d\n", unit)
+					return
+				}
+				cbLog := math.Log(cbDists[g] / cbDists[c])
+				// euclidean codebook distances are squared
+				if m.config.Metric == "euclidean" {
+					cbLog = cbLog / 2
+				}
+				logSum += cbLog + math.Log(gridDist[g]/gridDist[c])
+				product += logSum / float64(2*(k+1))
+			}
+		}
+	})
+	if err != nil {
+		return 0.0, err
+	}
+	if prodErr != nil {
+		return 0.0, prodErr
+	}
+	return product / float64(mUnits*(mUnits-1)), nil
+}
+
+// CombinedError returns mean combined error of the map for the data samples stored in rows
+// of data matrix. Combined error of a data sample is the sum of its quantization error and
+// the length of the shortest path from its BMU to its second BMU, which leads through the
+// codebook vectors of neighbouring SOM units.
+// See Kaski S, Lagus K: Comparing Self-Organizing Maps (1996).
+// It returns error if data is nil or if its dimension does not match the codebook dimension.
+func (m Map) CombinedError(data *mat64.Dense) (float64, error) {
+	mUnits, _ := m.codebook.Dims()
+	// data samples grouped by their BMUs along with their second BMUs
+	seconds := make([][]int, mUnits)
+	combined := 0.0
+	err := m.unitBlocks(data, func(from int, dists *mat64.Dense) {
+		size, _ := dists.Dims()
+		for i := 0; i < size; i++ {
+			row := dists.RawRowView(i)
+			first, second := twoClosest(row)
+			seconds[first] = append(seconds[first], second)
+			qe := row[first]
+			if len(m.layers) < 2 && m.config.Metric == "euclidean" {
+				qe = math.Sqrt(qe)
+			}
+			combined += qe
+		}
+	})
+	if err != nil {
+		return 0.0, err
+	}
+	// shortest paths are computed once for every BMU
+	for unit := range seconds {
+		if len(seconds[unit]) == 0 {
+			continue
+		}
+		paths, err := m.shortestPaths(unit)
+		if err != nil {
+			return 0.0, err
+		}
+		for _, second := range seconds[unit] {
+			combined += paths[second]
+		}
+	}
+	rows, _ := data.Dims()
+	return combined / float64(rows), nil
+}
+
+// shortestPaths returns lengths of the shortest paths from unit to all SOM units which lead
+// through the codebook vectors of neighbouring SOM units. Path lengths are computed using
+// Dijkstra algorithm with edges weighted by the distances between codebook vectors measured
+// in the same way as in BMU search: see codebookDist.
+func (m Map) shortestPaths(unit int) ([]float64, error) {
+	mUnits, _ := m.codebook.Dims()
+	paths := make([]float64, mUnits)
+	for i := range paths {
+		paths[i] = math.Inf(1)
+	}
+	paths[unit] = 0
+	visited := make([]bool, mUnits)
+	queue := &pathQueue{{unit: unit}}
+	for queue.Len() > 0 {
+		p := heap.Pop(queue).(path)
+		if visited[p.unit] {
+			continue
+		}
+		visited[p.unit] = true
+		neighbs, err := m.grid.Neighbors(p.unit)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range neighbs {
+			if visited[n] {
+				continue
+			}
+			dist, err := m.codebookDist(p.unit, n)
+			if err != nil {
+				return nil, err
+			}
+			if p.length+dist < paths[n] {
+				paths[n] = p.length + dist
+				heap.Push(queue, path{unit: n, length: paths[n]})
+			}
+		}
+	}
+	return paths, nil
+}
+
+// path is a path to SOM unit along with its length
+type path struct {
+	unit   int
+	length float64
+}
+
+// pathQueue is a priority queue of paths ordered by their lengths
+type pathQueue []path
+
+func (q pathQueue) Len() int            { return len(q) }
+func (q pathQueue) Less(i, j int) bool  { return q[i].length < q[j].length }
+func (q pathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x interface{}) { *q = append(*q, x.(path)) }
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	p := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return p
+}
+
+// rank stores ranks of all elements of dists except for the element with index self in ranks.
+// The closest element has rank 1, self has rank 0. Ties in dists are broken by ties, if it is
+// not nil, and then by element indices. order is filled with indices of the ranked elements.
+func rank(ranks, order []int, self int, dists, ties []float64) {
+	for i, j := 0, 0; i < len(dists); i++ {
+		if i != self {
+			order[j] = i
+			j++
+		}
+	}
+	sort.Sort(byRank{idx: order, dists: dists, ties: ties})
+	ranks[self] = 0
+	for r, i := range order {
+		ranks[i] = r + 1
+	}
+}
+
+// byRank sorts indices by their distances, ties and indices
+type byRank struct {
+	idx   []int
+	dists []float64
+	ties  []float64
+}
+
+func (b byRank) Len() int      { return len(b.idx) }
+func (b byRank) Swap(i, j int) { b.idx[i], b.idx[j] = b.idx[j], b.idx[i] }
+func (b byRank) Less(i, j int) bool {
+	a, c := b.idx[i], b.idx[j]
+	if b.dists[a] != b.dists[c] {
+		return b.dists[a] < b.dists[c]
+	}
+	if b.ties != nil && b.ties[a] != b.ties[c] {
+		return b.ties[a] < b.ties[c]
+	}
+	return a < c
+}
@@ -0,0 +1,338 @@
+package som
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+// newQualityMap returns a 2x2 map whose unit 3 is twisted close to unit 0 in data space
+func newQualityMap(metric string) (*Map, error) {
+	c := &Config{
+		Dims:   []int{2, 2},
+		Grid:   "planar",
+		UShape: "rectangle",
+		InitFunc: func(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
+			return mat64.NewDense(4, 2, []float64{
+				0.0, 0.0,
+				0.0, 1.0,
+				1.0, 0.0,
+				0.1, 0.1,
+			}), nil
+		},
+		RDecay:   "lin",
+		NeighbFn: "gaussian",
+		LDecay:   "lin",
+		Metric:   metric,
+	}
+	return NewMap(c, mat64.NewDense(1, 2, nil))
+}
+
+func TestQuantError(t *testing.T) {
+	assert := assert.New(t)
+
+	data := mat64.NewDense(4, 2, []float64{
+		0.0, 0.0,
+		0.0, 1.0,
+		1.0, 0.0,
+		0.1, 0.2,
+	})
+	for _, metric := range []string{"euclidean", "manhattan"} {
+		m, err := newQualityMap(metric)
+		assert.NoError(err)
+		qe, err := m.QuantError(data)
+		assert.NoError(err)
+		assert.InDelta(0.1/4, qe, 1e-9)
+		assert.InDelta(meanQuantError(m, data), qe, 1e-9)
+	}
+
+	m, err := newQualityMap("euclidean")
+	assert.NoError(err)
+	qe, err := m.QuantError(nil)
+	assert.Equal(0.0, qe)
+	assert.Error(err)
+	qe, err = m.QuantError(mat64.NewDense(1, 3, nil))
+	assert.Equal(0.0, qe)
+	assert.Error(err)
+}
+
+func TestTopoError(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := newQualityMap("euclidean")
+	assert.NoError(err)
+	testCases := []struct {
+		data []float64
+		te   float64
+	}{
+		// BMU 0, second BMU 3 are not neighbours
+		{[]float64{0.0, 0.0}, 1.0},
+		// BMU 1, second BMU 3 are neighbours
+		{[]float64{0.0, 1.0}, 0.0},
+		// BMU 2, second BMU 3 are neighbours
+		{[]float64{1.0, 0.0}, 0.0},
+		{[]float64{0.0, 0.0, 0.0, 1.0, 1.0, 0.0, 0.1, 0.2}, 0.5},
+	}
+
+	for _, tc := range testCases {
+		data := mat64.NewDense(len(tc.data)/2, 2, tc.data)
+		te, err := m.TopoError(data)
+		assert.NoError(err)
+		assert.InDelta(tc.te, te, 1e-9)
+		// compare with KBMUs
+		rows, _ := data.Dims()
+		errs := 0
+		for i := 0; i < rows; i++ {
+			units, _, err := m.KBMUs(data.RowView(i), 2)
+			assert.NoError(err)
+			neighbs, err := m.Grid().Neighbors(units[0])
+			assert.NoError(err)
+			adjacent := false
+			for _, n := range neighbs {
+				adjacent = adjacent || n == units[1]
+			}
+			if !adjacent {
+				errs++
+			}
+		}
+		assert.InDelta(float64(errs)/float64(rows), te, 1e-9)
+	}
+
+	te, err := m.TopoError(nil)
+	assert.Equal(0.0, te)
+	assert.Error(err)
+}
+
+func TestDistortion(t *testing.T) {
+	assert := assert.New(t)
+
+	data := mat64.NewDense(4, 2, []float64{
+		0.0, 0.0,
+		0.0, 1.0,
+		1.0, 0.5,
+		0.1, 0.2,
+	})
+	for _, metric := range []string{"euclidean", "sqeuclidean", "manhattan"} {
+		m, err := newQualityMap(metric)
+		assert.NoError(err)
+		for _, radius := range []float64{0.5, 1.0, 2.0} {
+			distortion, err := m.Distortion(data, radius)
+			assert.NoError(err)
+			// reference distortion
+			exp := 0.0
+			for i := 0; i < 4; i++ {
+				bmu, _, err := m.BMU(data.RowView(i))
+				assert.NoError(err)
+				for j := 0; j < 4; j++ {
+					gridDist, err := m.Grid().Dist(bmu, j)
+					assert.NoError(err)
+					dist, err := Distance(metric, data.RowView(i), m.Codebook().RowView(j))
+					assert.NoError(err)
+					if metric != "sqeuclidean" {
+						dist = dist * dist
+					}
+					exp += Gaussian(gridDist, radius) * dist
+				}
+			}
+			assert.InDelta(exp/4, distortion, 1e-9)
+		}
+	}
+
+	m, err := newQualityMap("euclidean")
+	assert.NoError(err)
+	// distortion decreases with radius as the neighbourhood shrinks
+	d1, err := m.Distortion(data, 1.0)
+	assert.NoError(err)
+	d2, err := m.Distortion(data, 0.1)
+	assert.NoError(err)
+	assert.True(d2 < d1)
+	// with vanishing radius distortion approaches mean squared quantization error
+	qe := 0.0
+	_, dists, err := m.BMUsFor(data)
+	assert.NoError(err)
+	for _, dist := range dists {
+		qe += dist * dist
+	}
+	assert.InDelta(qe/4, d2, 1e-9)
+
+	for _, radius := range []float64{0.0, -1.0, math.Inf(-1)} {
+		distortion, err := m.Distortion(data, radius)
+		assert.Equal(0.0, distortion)
+		assert.Error(err)
+	}
+	distortion, err := m.Distortion(nil, 1.0)
+	assert.Equal(0.0, distortion)
+	assert.Error(err)
+}
+
+// newLineMap returns a 1D map whose codebook vectors lie on a line in the order of their units.
+// If twist is true, codebook vectors of the first and the middle unit are swapped.
+func newLineMap(units int, twist bool) (*Map, error) {
+	c := &Config{
+		Dims:   []int{units},
+		Grid:   "planar",
+		UShape: "rectangle",
+		InitFunc: func(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
+			codebook := mat64.NewDense(units, 2, nil)
+			for i := 0; i < units; i++ {
+				codebook.Set(i, 0, float64(i))
+			}
+			if twist {
+				codebook.Set(0, 0, float64(units/2))
+				codebook.Set(units/2, 0, 0.0)
+			}
+			return codebook, nil
+		},
+		RDecay:   "lin",
+		NeighbFn: "gaussian",
+		LDecay:   "lin",
+		Metric:   "euclidean",
+	}
+	return NewMap(c, mat64.NewDense(1, 2, nil))
+}
+
+func TestTrustworthinessContinuity(t *testing.T) {
+	assert := assert.New(t)
+
+	// data samples lie on the codebook vectors
+	data := mat64.NewDense(10, 2, nil)
+	for i := 0; i < 10; i++ {
+		data.Set(i, 0, float64(i))
+	}
+	m, err := newLineMap(10, false)
+	assert.NoError(err)
+	for _, k := range []int{1, 2, 5} {
+		trust, err := m.Trustworthiness(data, k)
+		assert.NoError(err)
+		assert.InDelta(1.0, trust, 1e-9)
+		cont, err := m.Continuity(data, k)
+		assert.NoError(err)
+		assert.InDelta(1.0, cont, 1e-9)
+	}
+	// twisted map doesn't preserve neighbourhoods
+	mTwist, err := newLineMap(10, true)
+	assert.NoError(err)
+	for _, k := range []int{1, 2, 5} {
+		trust, err := mTwist.Trustworthiness(data, k)
+		assert.NoError(err)
+		assert.True(trust < 1.0 && trust >= 0.0)
+		cont, err := mTwist.Continuity(data, k)
+		assert.NoError(err)
+		assert.True(cont < 1.0 && cont >= 0.0)
+	}
+	// samples mapped to the same unit are ordered by their data distances
+	dup := mat64.NewDense(10, 2, nil)
+	for i := 0; i < 10; i++ {
+		dup.Set(i, 0, float64(i/2*2)+0.1*float64(i%2))
+	}
+	trust, err := m.Trustworthiness(dup, 1)
+	assert.NoError(err)
+	assert.InDelta(1.0, trust, 1e-9)
+
+	for _, k := range []int{0, -1, 7} {
+		trust, err := m.Trustworthiness(data, k)
+		assert.Equal(0.0, trust)
+		assert.Error(err)
+		cont, err := m.Continuity(data, k)
+		assert.Equal(0.0, cont)
+		assert.Error(err)
+	}
+	trust, err = m.Trustworthiness(nil, 1)
+	assert.Equal(0.0, trust)
+	assert.Error(err)
+}
+
+func TestTopoProduct(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := newLineMap(10, false)
+	assert.NoError(err)
+	tp, err := m.TopoProduct()
+	assert.NoError(err)
+	assert.InDelta(0.0, tp, 1e-9)
+	// 1D codebook on 2D map: map dimension is too large
+	c := &Config{
+		Dims:   []int{2, 5},
+		Grid:   "planar",
+		UShape: "rectangle",
+		InitFunc: func(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
+			codebook := mat64.NewDense(10, 2, nil)
+			for i := 0; i < 10; i++ {
+				codebook.Set(i, 0, float64(i))
+			}
+			return codebook, nil
+		},
+		RDecay:   "lin",
+		NeighbFn: "gaussian",
+		LDecay:   "lin",
+	}
+	m2D, err := NewMap(c, mat64.NewDense(1, 2, nil))
+	assert.NoError(err)
+	tp2D, err := m2D.TopoProduct()
+	assert.NoError(err)
+	assert.True(tp2D > 0)
+	// 2D codebook on 1D map: map dimension is too small
+	c.Dims = []int{9}
+	c.InitFunc = func(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
+		codebook := mat64.NewDense(9, 2, nil)
+		for i := 0; i < 9; i++ {
+			// snake through 3x3 square
+			x := i % 3
+			if (i/3)%2 == 1 {
+				x = 2 - x
+			}
+			codebook.Set(i, 0, float64(x))
+			codebook.Set(i, 1, float64(i/3))
+		}
+		return codebook, nil
+	}
+	m1D, err := NewMap(c, mat64.NewDense(1, 2, nil))
+	assert.NoError(err)
+	tp1D, err := m1D.TopoProduct()
+	assert.NoError(err)
+	assert.True(tp1D < 0)
+	// identical codebook vectors
+	m.Codebook().Set(0, 0, 1.0)
+	tp, err = m.TopoProduct()
+	assert.Equal(0.0, tp)
+	assert.Error(err)
+}
+
+func TestCombinedError(t *testing.T) {
+	assert := assert.New(t)
+
+	m, err := newQualityMap("euclidean")
+	assert.NoError(err)
+	testCases := []struct {
+		data []float64
+		ce   float64
+	}{
+		// BMU 0, second BMU 3 is 2 steps away
+		{[]float64{0.0, 0.0}, 1.0 + math.Sqrt(0.82)},
+		// BMU 1, second BMU 3 is a neighbour
+		{[]float64{0.0, 1.0}, math.Sqrt(0.82)},
+		// BMU 3, second BMU 0 is 2 steps away
+		{[]float64{0.1, 0.2}, 0.1 + 1.0 + math.Sqrt(0.82)},
+		{[]float64{0.0, 0.0, 0.0, 1.0}, (1.0 + 2*math.Sqrt(0.82)) / 2},
+	}
+
+	for _, tc := range testCases {
+		data := mat64.NewDense(len(tc.data)/2, 2, tc.data)
+		ce, err := m.CombinedError(data)
+		assert.NoError(err)
+		assert.InDelta(tc.ce, ce, 1e-9)
+		// combined error is never smaller than quantization error
+		qe, err := m.QuantError(data)
+		assert.NoError(err)
+		assert.True(ce > qe)
+	}
+
+	ce, err := m.CombinedError(nil)
+	assert.Equal(0.0, ce)
+	assert.Error(err)
+	ce, err = m.CombinedError(mat64.NewDense(1, 3, nil))
+	assert.Equal(0.0, ce)
+	assert.Error(err)
+}
@@ -16,17 +16,30 @@ type CoordsInitFunc func(string, []int) (*mat64.Dense, error)
 // NeighbFunc defines SOM neighbourhood function
 type NeighbFunc func(float64, float64) float64
 
+// DistanceFunc defines a distance metric between two vectors
+type DistanceFunc func(*mat64.Vector, *mat64.Vector) (float64, error)
+
+// DecayFunc defines SOM learning rate and radius decay function.
+// It returns a value decayed from initial towards final value at given step out of total steps.
+type DecayFunc func(init, final float64, step, steps int) float64
+
+// UnscaleFunc transforms scaled data stored in rows of matrix back to original data units
+type UnscaleFunc func(*mat64.Dense) (*mat64.Dense, error)
+
 // Map is a Self Organizing Map (SOM)
 type Map struct {
 	// codebook is a matrix which contains SOM codebook vectors
 	// codebook dimensions: SOM units x data features
 	codebook *mat64.Dense
-	// gridDist is a symmetric hollow matrix that maps distances between SOM units
-	// gridDist dimesions: SOM units x SOM units
-	gridDist *mat64.Dense
+	// grid is the SOM grid which computes distances between SOM units on demand
+	grid *Grid
+	// layers stores codebook layers with normalized weights
+	layers []Layer
 	// bmus stores codebook row indices of Best Match Units (BMU) for each data sample
 	// bmus length is equal to the number of the input data samples
 	bmus []int
+	// config stores a copy of the configuration the map was created with
+	config Config
 }
 
 // NewMap creates new SOM based on the provided configuration and input data
@@ -43,6 +56,13 @@ func NewMap(c *Config, data *mat64.Dense) (*Map, error) {
 	if c.InitFunc == nil {
 		c.InitFunc = RandInit
 	}
+	// if no distance metrics are specified, use euclidean distance
+	if c.Metric == "" {
+		c.Metric = "euclidean"
+	}
+	if c.GridMetric == "" {
+		c.GridMetric = "euclidean"
+	}
 	// if input data is empty throw error
 	if data == nil {
 		return nil, fmt.Errorf("Invalid input data: %v\n", data)
@@ -57,24 +77,27 @@ func NewMap(c *Config, data *mat64.Dense) (*Map, error) {
 	if err != nil {
 		return nil, err
 	}
-	// grid coordinates matrix
-	gridCoords, err := GridCoords(c.UShape, c.Dims)
+	// SOM grid
+	grid, err := NewGrid(c)
 	if err != nil {
 		return nil, err
 	}
-	// grid distance matrix
-	gridDist, err := DistanceMx("euclidean", gridCoords)
+	// codebook layers
+	layers, err := newLayers(c, data)
 	if err != nil {
 		return nil, err
 	}
-	rows, _ := data.Dims()
-	bmus := make([]int, rows)
-	// return pointer to new map
-	return &Map{
+	m := &Map{
 		codebook: codebook,
-		gridDist: gridDist,
-		bmus:     bmus,
-	}, nil
+		grid:     grid,
+		layers:   layers,
+		config:   *c,
+	}
+	// map data samples to their initial BMUs
+	if err := m.updateBMUs(data); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
 // Codebook returns a matrix which contains SOM codebook vectors
@@ -82,12 +105,20 @@ func (m Map) Codebook() *mat64.Dense {
 	return m.codebook
 }
 
-// GridDist returns a matrix which contains Euclidean distances between SOM units
+// Grid returns SOM grid
+func (m Map) Grid() *Grid {
+	return m.grid
+}
+
+// GridDist returns a matrix which contains distances between SOM units.
+// The matrix is computed on every call and its size grows quadratically with the number of
+// SOM units, so it should only be used with small maps. Training does not require it.
 func (m Map) GridDist() *mat64.Dense {
-	return m.gridDist
+	return m.grid.distMx()
 }
 
-// BMUs returns a slice which contains indices of Best Match Units (BMUs) of each input vector
+// BMUs returns a slice which contains indices of Best Match Units (BMUs) of each input vector.
+// BMUs are found when the map is created and updated at the end of training.
 func (m Map) BMUs() []int {
 	return m.bmus
 }
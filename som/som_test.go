@@ -88,6 +88,30 @@ func TestNewMap(t *testing.T) {
 	assert.Nil(m)
 	assert.Error(err)
 	cSom.InitFunc = RandInit
+	// distance metrics default to euclidean distance
+	origMetric, origGridMetric := cSom.Metric, cSom.GridMetric
+	m, err = NewMap(cSom, dataMx)
+	assert.NoError(err)
+	assert.Equal("euclidean", m.config.Metric)
+	assert.Equal("euclidean", m.config.GridMetric)
+	cSom.Metric, cSom.GridMetric = origMetric, origGridMetric
+	// grid distances computed using the requested metric
+	cSom.GridMetric = "manhattan"
+	m, err = NewMap(cSom, dataMx)
+	assert.NoError(err)
+	coords, _ := GridCoords(cSom.UShape, cSom.Dims)
+	gridDist, _ := DistanceMx("manhattan", coords)
+	assert.True(mat64.Equal(gridDist, m.GridDist()))
+	cSom.GridMetric = origGridMetric
+	// toroid grid distances wrap around
+	origDims, origGrid := cSom.Dims, cSom.Grid
+	cSom.Dims, cSom.Grid = []int{4, 3}, "toroid"
+	m, err = NewMap(cSom, dataMx)
+	assert.NoError(err)
+	coords, _ = GridCoords(cSom.UShape, cSom.Dims)
+	gridDist, _ = GridDistMx("euclidean", coords, GridPeriods("toroid", cSom.UShape, cSom.Dims, 0))
+	assert.True(mat64.Equal(gridDist, m.GridDist()))
+	cSom.Dims, cSom.Grid = origDims, origGrid
 }
 
 func TestCodebook(t *testing.T) {
@@ -0,0 +1,420 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/milosgajdos83/gosom/pkg/utils"
+)
+
+// Grid is a SOM grid: it holds SOM unit shape, grid dimensions, topology and unit coordinates.
+// Grid computes distances between SOM units on demand from their coordinates and can
+// optionally cache a limited number of rows of computed distances, so that the distances
+// of the most recently queried units don't need to be recomputed.
+// SOM units are indexed in the same order as the rows of the matrix returned by GridCoords.
+type Grid struct {
+	// uShape is the SOM unit shape: hexagon, rectangle
+	uShape string
+	// dims contains grid dimensions
+	dims []int
+	// topology is the type of the grid: planar, toroid, cylinder
+	topology string
+	// axis is the index of the dimension cylinder grid wraps around
+	axis int
+	// coords contains SOM unit grid coordinates stored by row
+	coords *mat64.Dense
+	// periods contains grid coordinate periods: see GridPeriods
+	periods []float64
+	// metric is the name of the grid distance metric
+	metric string
+	// distFn computes grid distance metric: nil for lattice metrics
+	distFn DistanceFunc
+	// lattice is true if the grid distance metric is one of the Lattice metrics
+	lattice bool
+	// cacheSize is the maximum number of cached distance rows: 0 disables caching
+	cacheSize int
+	// mu protects cache
+	mu sync.Mutex
+	// cache maps unit indices to their distances from all SOM units
+	cache map[int][]float64
+	// cached stores cached unit indices in the order they were cached
+	cached []int
+}
+
+// NewGrid creates new SOM grid based on the grid parameters of the provided configuration:
+// Dims, Grid, Axis, UShape, GridMetric and GridCache. If GridMetric is not set, Euclidean
+// distance is used to compute distances between SOM units. If GridMetric is one of the
+// Lattice metrics, distances between SOM units are the numbers of lattice steps between them.
+// It returns error if the grid configuration is invalid or if the grid coordinates could
+// not be computed.
+func NewGrid(c *Config) (*Grid, error) {
+	if err := validateGridConfig(c); err != nil {
+		return nil, err
+	}
+	metric := c.GridMetric
+	if metric == "" {
+		metric = "euclidean"
+	}
+	coords, err := CoordsInit[c.Grid](c.UShape, c.Dims)
+	if err != nil {
+		return nil, err
+	}
+	dims := make([]int, len(c.Dims))
+	copy(dims, c.Dims)
+	// lattice metrics have no distance function
+	distFn, _ := metricFunc(metric)
+	return &Grid{
+		uShape:    c.UShape,
+		dims:      dims,
+		topology:  c.Grid,
+		axis:      c.Axis,
+		coords:    coords,
+		periods:   GridPeriods(c.Grid, c.UShape, c.Dims, c.Axis),
+		metric:    metric,
+		distFn:    distFn,
+		lattice:   Lattice[metric],
+		cacheSize: c.GridCache,
+		cache:     make(map[int][]float64),
+	}, nil
+}
+
+// Units returns the number of SOM units
+func (g *Grid) Units() int {
+	rows, _ := g.coords.Dims()
+	return rows
+}
+
+// Dims returns grid dimensions
+func (g *Grid) Dims() []int {
+	return g.dims
+}
+
+// UShape returns SOM unit shape
+func (g *Grid) UShape() string {
+	return g.uShape
+}
+
+// Topology returns the type of the grid: planar, toroid, cylinder
+func (g *Grid) Topology() string {
+	return g.topology
+}
+
+// Coords returns a matrix which contains SOM unit coordinates stored by row
+func (g *Grid) Coords() *mat64.Dense {
+	return g.coords
+}
+
+// Dist returns grid distance between units i and j.
+// It returns error if either of the unit indices is invalid.
+func (g *Grid) Dist(i, j int) (float64, error) {
+	if err := g.validateUnits(i, j); err != nil {
+		return 0.0, err
+	}
+	_, cols := g.coords.Dims()
+	return g.unitDist(i, j, mat64.NewVector(cols, nil), mat64.NewVector(cols, nil)), nil
+}
+
+// Hops returns lattice hop distance between units i and j i.e. the smallest number of steps
+// between neighbouring units needed to get from unit i to unit j. Hexagon units have six
+// neighbours, rectangle units have two neighbours along each grid dimension regardless of
+// the grid distance metric.
+// Hop distances respect the grid topology.
+// It returns error if either of the unit indices is invalid.
+func (g *Grid) Hops(i, j int) (int, error) {
+	if err := g.validateUnits(i, j); err != nil {
+		return 0, err
+	}
+	return g.hops(i, j, false), nil
+}
+
+// Neighbors returns indices of the units which are the immediate lattice neighbours of unit.
+// It returns error if the unit index is invalid.
+func (g *Grid) Neighbors(unit int) ([]int, error) {
+	return g.NeighborsWithin(unit, 1)
+}
+
+// NeighborsWithin returns indices of the units which are at most radius hops away from unit.
+// The unit itself is not included in the returned slice.
+// It returns error if the unit index is invalid or if radius is negative.
+func (g *Grid) NeighborsWithin(unit, radius int) ([]int, error) {
+	if err := g.validateUnits(unit); err != nil {
+		return nil, err
+	}
+	if radius < 0 {
+		return nil, fmt.Errorf("Invalid radius: %d\n", radius)
+	}
+	// only the units in a box around unit can be within radius:
+	// hexagon column offsets can exceed the number of hops
+	span := radius
+	if strings.EqualFold(g.uShape, "hexagon") {
+		span = 2 * radius
+	}
+	pos := g.position(unit)
+	from := make([]int, len(pos))
+	to := make([]int, len(pos))
+	for k, p := range pos {
+		from[k], to[k] = p-span, p+span
+		if g.wraps(k) && to[k]-from[k] >= g.dims[k] {
+			from[k], to[k] = 0, g.dims[k]-1
+		}
+		if !g.wraps(k) && from[k] < 0 {
+			from[k] = 0
+		}
+		if !g.wraps(k) && to[k] >= g.dims[k] {
+			to[k] = g.dims[k] - 1
+		}
+	}
+	var neighbs []int
+	box := make([]int, len(pos))
+	copy(box, from)
+	for {
+		i, stride := 0, 1
+		for k, p := range box {
+			i += ((p%g.dims[k] + g.dims[k]) % g.dims[k]) * stride
+			stride *= g.dims[k]
+		}
+		if i != unit && g.hops(unit, i, false) <= radius {
+			neighbs = append(neighbs, i)
+		}
+		// move to the next position in the box
+		k := 0
+		for ; k < len(box) && box[k] == to[k]; k++ {
+			box[k] = from[k]
+		}
+		if k == len(box) {
+			break
+		}
+		box[k]++
+	}
+	sort.Ints(neighbs)
+	return neighbs, nil
+}
+
+// Connectivity returns a slice which contains indices of the immediate neighbours of all SOM
+// units: see Neighbors. It can be used to restrict clustering of codebook vectors to clusters
+// of neighbouring SOM units.
+func (g *Grid) Connectivity() [][]int {
+	conn := make([][]int, g.Units())
+	for unit := range conn {
+		// unit index is always valid
+		conn[unit], _ = g.Neighbors(unit)
+	}
+	return conn
+}
+
+// Index returns index of the unit at the given position in the grid. Position contains
+// unit indices along each grid dimension i.e. row and column in 2D grids.
+// It returns error if the position is outside the grid.
+func (g *Grid) Index(pos ...int) (int, error) {
+	if len(pos) != len(g.dims) {
+		return -1, fmt.Errorf("Incorrect position dimensions: %v\n", pos)
+	}
+	unit, stride := 0, 1
+	for i, p := range pos {
+		if p < 0 || p >= g.dims[i] {
+			return -1, fmt.Errorf("Position outside the grid: %v\n", pos)
+		}
+		unit += p * stride
+		stride *= g.dims[i]
+	}
+	return unit, nil
+}
+
+// Position returns position of the unit in the grid. Position contains unit indices
+// along each grid dimension i.e. row and column in 2D grids.
+// It returns error if the unit index is invalid.
+func (g *Grid) Position(unit int) ([]int, error) {
+	if err := g.validateUnits(unit); err != nil {
+		return nil, err
+	}
+	return g.position(unit), nil
+}
+
+// position returns position of the unit in the grid
+func (g *Grid) position(unit int) []int {
+	pos := make([]int, len(g.dims))
+	for i, dim := range g.dims {
+		pos[i] = unit % dim
+		unit /= dim
+	}
+	return pos
+}
+
+// wraps returns true if the grid wraps around the dimension with index dim
+func (g *Grid) wraps(dim int) bool {
+	return g.topology == "toroid" || (g.topology == "cylinder" && g.axis == dim)
+}
+
+// hops returns lattice hop distance between units i and j.
+// If chebyshev is true, rectangle units have all adjacent units as their neighbours.
+func (g *Grid) hops(i, j int, chebyshev bool) int {
+	a, b := g.position(i), g.position(j)
+	if strings.EqualFold(g.uShape, "hexagon") && len(g.dims) == 2 {
+		return g.hexHops(a, b)
+	}
+	hops := 0
+	for k := range a {
+		diff := utils.IntAbs(a[k] - b[k])
+		if g.wraps(k) && g.dims[k]-diff < diff {
+			diff = g.dims[k] - diff
+		}
+		if !chebyshev {
+			hops += diff
+		} else if diff > hops {
+			hops = diff
+		}
+	}
+	return hops
+}
+
+// hexHops returns hop distance between hexagon units at positions a and b.
+// If the grid wraps around, the shortest distance between b and its wrapped copies is returned.
+func (g *Grid) hexHops(a, b []int) int {
+	rowShifts, colShifts := []int{0}, []int{0}
+	if g.wraps(0) {
+		rowShifts = []int{-g.dims[0], 0, g.dims[0]}
+	}
+	if g.wraps(1) {
+		colShifts = []int{-g.dims[1], 0, g.dims[1]}
+	}
+	hops := -1
+	for _, dr := range rowShifts {
+		for _, dc := range colShifts {
+			if h := hexDist(a[0], a[1], b[0]+dr, b[1]+dc); hops < 0 || h < hops {
+				hops = h
+			}
+		}
+	}
+	return hops
+}
+
+// hexDist returns hop distance between hexagons in rows r1, r2 and columns c1, c2.
+// Odd rows are shifted by half of the hexagon, so the offset coordinates are converted
+// to cube coordinates before calculating the distance.
+func hexDist(r1, c1, r2, c2 int) int {
+	q1 := c1 - (r1-(r1&1))/2
+	q2 := c2 - (r2-(r2&1))/2
+	dq, dr := q1-q2, r1-r2
+	return (utils.IntAbs(dq) + utils.IntAbs(dr) + utils.IntAbs(dq+dr)) / 2
+}
+
+// validateUnits checks if the supplied unit indices are valid.
+// It returns error if any of the indices is outside the grid.
+func (g *Grid) validateUnits(units ...int) error {
+	for _, unit := range units {
+		if unit < 0 || unit >= g.Units() {
+			return fmt.Errorf("Invalid unit index: %d\n", unit)
+		}
+	}
+	return nil
+}
+
+// row returns a slice which contains grid distances of unit i from all SOM units.
+// The returned slice must not be modified as it might be cached.
+func (g *Grid) row(i int) []float64 {
+	if g.cacheSize > 0 {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		if row, ok := g.cache[i]; ok {
+			return row
+		}
+	}
+	mUnits, cols := g.coords.Dims()
+	diff := mat64.NewVector(cols, nil)
+	zero := mat64.NewVector(cols, nil)
+	row := make([]float64, mUnits)
+	for j := range row {
+		row[j] = g.unitDist(i, j, diff, zero)
+	}
+	if g.cacheSize > 0 {
+		// evict the oldest cached row if the cache is full
+		if len(g.cached) == g.cacheSize {
+			delete(g.cache, g.cached[0])
+			g.cached = g.cached[1:]
+		}
+		g.cache[i] = row
+		g.cached = append(g.cached, i)
+	}
+	return row
+}
+
+// distMx returns a symmetric hollow matrix which contains grid distances between all SOM units
+func (g *Grid) distMx() *mat64.Dense {
+	if g.lattice {
+		mUnits := g.Units()
+		distMx := mat64.NewDense(mUnits, mUnits, nil)
+		for i := 0; i < mUnits; i++ {
+			for j := i + 1; j < mUnits; j++ {
+				dist := g.unitDist(i, j, nil, nil)
+				distMx.Set(i, j, dist)
+				distMx.Set(j, i, dist)
+			}
+		}
+		return distMx
+	}
+	// GridDistMx can only fail on invalid input which is checked in NewGrid
+	distMx, _ := GridDistMx(g.metric, g.coords, g.periods)
+	return distMx
+}
+
+// maxDist returns an upper bound of the distances between SOM units.
+// It is calculated from the extents of the grid coordinates along all axes,
+// so it's equal to the largest distance between SOM units on planar grids.
+// Lattice distances are maximized over the distances from the grid corners.
+func (g *Grid) maxDist() float64 {
+	if g.lattice {
+		return g.maxLatticeDist()
+	}
+	_, cols := g.coords.Dims()
+	extents := mat64.NewVector(cols, nil)
+	for j := 0; j < cols; j++ {
+		col := g.coords.ColView(j)
+		extent := mat64.Max(col) - mat64.Min(col)
+		if g.periods[j] > 0 {
+			extent = math.Min(extent, g.periods[j]/2)
+		}
+		extents.SetVec(j, extent)
+	}
+	dist, _ := g.distFn(extents, mat64.NewVector(cols, nil))
+	return dist
+}
+
+// maxLatticeDist returns the largest lattice distance between the grid corners and all SOM units.
+// The farthest units are always found in the grid corners on planar grids and every unit along
+// the wrapped dimensions is equally far from the rest of the grid, so the distance is exact.
+func (g *Grid) maxLatticeDist() float64 {
+	max := 0.0
+	pos := make([]int, len(g.dims))
+	for c := 0; c < 1<<uint(len(g.dims)); c++ {
+		for k, dim := range g.dims {
+			pos[k] = 0
+			if c&(1<<uint(k)) != 0 {
+				pos[k] = dim - 1
+			}
+		}
+		corner, _ := g.Index(pos...)
+		for j := 0; j < g.Units(); j++ {
+			max = math.Max(max, g.unitDist(corner, j, nil, nil))
+		}
+	}
+	return max
+}
+
+// unitDist computes grid distance between units i and j using diff and zero as workspace.
+// zero must be a zero vector; both vectors must have as many elements as there are coordinates.
+// Lattice distances don't need any workspace, so both vectors can be nil.
+func (g *Grid) unitDist(i, j int, diff, zero *mat64.Vector) float64 {
+	if g.lattice {
+		return float64(g.hops(i, j, g.metric == "lattice-chebyshev"))
+	}
+	for k := range g.periods {
+		diff.SetVec(k, wrapDiff(g.coords.At(i, k), g.coords.At(j, k), g.periods[k]))
+	}
+	// distFn can only fail on invalid vectors which are allocated by the callers
+	dist, _ := g.distFn(diff, zero)
+	return dist
+}
@@ -0,0 +1,351 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGrid(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		grid   string
+		uShape string
+		dims   []int
+		metric string
+	}{
+		{"planar", "hexagon", []int{4, 3}, "euclidean"},
+		{"planar", "rectangle", []int{4, 3}, "manhattan"},
+		{"toroid", "hexagon", []int{4, 3}, "euclidean"},
+		{"cylinder", "rectangle", []int{2, 3, 2}, "chebyshev"},
+		{"planar", "rectangle", []int{5}, "euclidean"},
+	}
+
+	for _, tc := range testCases {
+		coords, err := GridCoords(tc.uShape, tc.dims)
+		assert.NoError(err)
+		periods := GridPeriods(tc.grid, tc.uShape, tc.dims, 0)
+		expDist, err := GridDistMx(tc.metric, coords, periods)
+		assert.NoError(err)
+		for _, cacheSize := range []int{0, 2} {
+			c := &Config{
+				Dims:       tc.dims,
+				Grid:       tc.grid,
+				UShape:     tc.uShape,
+				GridMetric: tc.metric,
+				GridCache:  cacheSize,
+			}
+			grid, err := NewGrid(c)
+			assert.NoError(err)
+			assert.Equal(tc.dims, grid.Dims())
+			assert.Equal(tc.uShape, grid.UShape())
+			assert.Equal(tc.grid, grid.Topology())
+			assert.True(mat64.Equal(coords, grid.Coords()))
+			mUnits := grid.Units()
+			assert.Equal(mUnits, len(grid.row(0)))
+			// query every row twice to exercise the cache
+			for r := 0; r < 2; r++ {
+				for i := 0; i < mUnits; i++ {
+					row := grid.row(i)
+					for j := 0; j < mUnits; j++ {
+						assert.Equal(expDist.At(i, j), row[j])
+						dist, err := grid.Dist(i, j)
+						assert.NoError(err)
+						assert.Equal(expDist.At(i, j), dist)
+					}
+				}
+			}
+			assert.True(len(grid.cache) <= cacheSize)
+			assert.True(len(grid.cached) <= cacheSize)
+			assert.True(mat64.Equal(expDist, grid.distMx()))
+			assert.True(mat64.Max(expDist) <= grid.maxDist()+1e-9, tc.grid)
+			if tc.grid == "planar" {
+				assert.InDelta(mat64.Max(expDist), grid.maxDist(), 1e-9)
+			}
+		}
+	}
+
+	// default grid metric
+	grid, err := NewGrid(&Config{Dims: []int{2, 2}, Grid: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	assert.Equal("euclidean", grid.metric)
+	// invalid grid configuration
+	errCases := []*Config{
+		{Dims: []int{2, 2}, Grid: "planar", UShape: "rectangle", GridMetric: "foobar"},
+		{Dims: []int{2, 2}, Grid: "foobar", UShape: "rectangle"},
+		{Dims: []int{2, 2}, Grid: "planar", UShape: "foobar"},
+		{Dims: []int{2, 2}, Grid: "planar", UShape: "rectangle", GridCache: -1},
+	}
+	for _, c := range errCases {
+		grid, err := NewGrid(c)
+		assert.Nil(grid)
+		assert.Error(err)
+	}
+}
+
+func TestGridIndexPosition(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		dims []int
+		unit int
+		pos  []int
+	}{
+		{[]int{4, 3}, 0, []int{0, 0}},
+		{[]int{4, 3}, 5, []int{1, 1}},
+		{[]int{4, 3}, 11, []int{3, 2}},
+		{[]int{5}, 3, []int{3}},
+		{[]int{2, 3, 2}, 7, []int{1, 0, 1}},
+	}
+
+	for _, tc := range testCases {
+		grid, err := NewGrid(&Config{Dims: tc.dims, Grid: "planar", UShape: "rectangle"})
+		assert.NoError(err)
+		pos, err := grid.Position(tc.unit)
+		assert.NoError(err)
+		assert.Equal(tc.pos, pos)
+		unit, err := grid.Index(tc.pos...)
+		assert.NoError(err)
+		assert.Equal(tc.unit, unit)
+		// position coordinates must match grid coordinates
+		if len(tc.dims) == 2 {
+			assert.Equal(float64(tc.pos[1]), grid.Coords().At(tc.unit, 0))
+			assert.Equal(float64(tc.pos[0]), grid.Coords().At(tc.unit, 1))
+		}
+	}
+
+	grid, err := NewGrid(&Config{Dims: []int{4, 3}, Grid: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	// invalid units
+	for _, unit := range []int{-1, 12} {
+		pos, err := grid.Position(unit)
+		assert.Nil(pos)
+		assert.Error(err)
+	}
+	// invalid positions
+	for _, pos := range [][]int{{1}, {1, 1, 1}, {4, 0}, {0, -1}} {
+		unit, err := grid.Index(pos...)
+		assert.Equal(-1, unit)
+		assert.Error(err)
+	}
+}
+
+func TestGridHops(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		grid   string
+		uShape string
+		dims   []int
+		from   []int
+		to     []int
+		hops   int
+	}{
+		{"planar", "rectangle", []int{4, 3}, []int{0, 0}, []int{3, 2}, 5},
+		{"planar", "rectangle", []int{4, 3}, []int{1, 1}, []int{1, 1}, 0},
+		{"toroid", "rectangle", []int{4, 3}, []int{0, 0}, []int{3, 2}, 2},
+		{"cylinder", "rectangle", []int{4, 3}, []int{0, 0}, []int{3, 2}, 3},
+		{"planar", "rectangle", []int{5}, []int{0}, []int{4}, 4},
+		{"toroid", "rectangle", []int{5}, []int{0}, []int{4}, 1},
+		{"planar", "rectangle", []int{2, 3, 2}, []int{0, 0, 0}, []int{1, 2, 1}, 4},
+		// odd rows are shifted to the right
+		{"planar", "hexagon", []int{4, 4}, []int{0, 0}, []int{1, 0}, 1},
+		{"planar", "hexagon", []int{4, 4}, []int{1, 0}, []int{0, 1}, 1},
+		{"planar", "hexagon", []int{4, 4}, []int{1, 1}, []int{0, 0}, 2},
+		{"planar", "hexagon", []int{4, 4}, []int{0, 0}, []int{3, 3}, 5},
+		{"planar", "hexagon", []int{4, 4}, []int{0, 3}, []int{3, 0}, 4},
+		{"toroid", "hexagon", []int{4, 4}, []int{0, 0}, []int{3, 3}, 1},
+		{"toroid", "hexagon", []int{4, 4}, []int{0, 0}, []int{0, 3}, 1},
+	}
+
+	for _, tc := range testCases {
+		grid, err := NewGrid(&Config{Dims: tc.dims, Grid: tc.grid, UShape: tc.uShape})
+		assert.NoError(err)
+		from, err := grid.Index(tc.from...)
+		assert.NoError(err)
+		to, err := grid.Index(tc.to...)
+		assert.NoError(err)
+		hops, err := grid.Hops(from, to)
+		assert.NoError(err)
+		assert.Equal(tc.hops, hops, "%s %s %v %v", tc.grid, tc.uShape, tc.from, tc.to)
+		hops, err = grid.Hops(to, from)
+		assert.NoError(err)
+		assert.Equal(tc.hops, hops)
+	}
+
+	grid, err := NewGrid(&Config{Dims: []int{4, 3}, Grid: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	hops, err := grid.Hops(0, 12)
+	assert.Equal(0, hops)
+	assert.Error(err)
+}
+
+func TestGridNeighbors(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		grid    string
+		uShape  string
+		dims    []int
+		radius  int
+		neighbs []int
+	}{
+		{"planar", "rectangle", []int{6, 6}, 1, []int{4}},
+		{"planar", "hexagon", []int{6, 6}, 1, []int{6}},
+		{"toroid", "rectangle", []int{6, 6}, 1, []int{4}},
+		{"toroid", "hexagon", []int{6, 6}, 1, []int{6}},
+		{"planar", "rectangle", []int{6, 6}, 2, []int{12}},
+		{"planar", "hexagon", []int{6, 6}, 2, []int{18}},
+		{"toroid", "rectangle", []int{2, 3, 4}, 1, []int{5}},
+	}
+
+	for _, tc := range testCases {
+		grid, err := NewGrid(&Config{Dims: tc.dims, Grid: tc.grid, UShape: tc.uShape})
+		assert.NoError(err)
+		// counts of neighbours of the inner units or of all units on toroid
+		counts := make(map[int]bool)
+		for unit := 0; unit < grid.Units(); unit++ {
+			neighbs, err := grid.NeighborsWithin(unit, tc.radius)
+			assert.NoError(err)
+			pos := grid.position(unit)
+			inner := true
+			for i, p := range pos {
+				if p < tc.radius || p >= tc.dims[i]-tc.radius {
+					inner = false
+				}
+			}
+			if inner || tc.grid == "toroid" {
+				counts[len(neighbs)] = true
+			}
+			for _, n := range neighbs {
+				assert.NotEqual(unit, n)
+				hops, err := grid.Hops(unit, n)
+				assert.NoError(err)
+				assert.True(hops <= tc.radius)
+			}
+		}
+		for _, n := range tc.neighbs {
+			assert.True(counts[n], "%s %s %v", tc.grid, tc.uShape, tc.dims)
+		}
+		assert.Equal(len(tc.neighbs), len(counts), "%s %s %v", tc.grid, tc.uShape, tc.dims)
+	}
+
+	// compare with all units within radius
+	grids := []*Config{
+		{Dims: []int{6, 5}, Grid: "toroid", UShape: "hexagon"},
+		{Dims: []int{7, 5}, Grid: "cylinder", UShape: "hexagon", Axis: 1},
+		{Dims: []int{3, 4, 5}, Grid: "cylinder", UShape: "rectangle", Axis: 2},
+		{Dims: []int{9}, Grid: "toroid", UShape: "rectangle"},
+	}
+	for _, c := range grids {
+		grid, err := NewGrid(c)
+		assert.NoError(err)
+		for _, radius := range []int{0, 1, 2, 3, 5} {
+			for unit := 0; unit < grid.Units(); unit++ {
+				var exp []int
+				for i := 0; i < grid.Units(); i++ {
+					if hops, _ := grid.Hops(unit, i); i != unit && hops <= radius {
+						exp = append(exp, i)
+					}
+				}
+				neighbs, err := grid.NeighborsWithin(unit, radius)
+				assert.NoError(err)
+				assert.Equal(exp, neighbs, "%v %d %d", c, unit, radius)
+			}
+		}
+	}
+
+	// hexagon neighbours in even and odd rows
+	grid, err := NewGrid(&Config{Dims: []int{4, 4}, Grid: "planar", UShape: "hexagon"})
+	assert.NoError(err)
+	unit, _ := grid.Index(2, 1)
+	neighbs, err := grid.Neighbors(unit)
+	assert.NoError(err)
+	assert.Equal([]int{1, 2, 3, 5, 7, 10}, neighbs)
+	// neighbours must be the closest units on the hexagon grid
+	for _, n := range neighbs {
+		dist, err := grid.Dist(unit, n)
+		assert.NoError(err)
+		assert.InDelta(1.0, dist, 1e-9)
+	}
+
+	neighbs, err = grid.Neighbors(-1)
+	assert.Nil(neighbs)
+	assert.Error(err)
+	neighbs, err = grid.NeighborsWithin(0, -1)
+	assert.Nil(neighbs)
+	assert.Error(err)
+}
+
+func TestGridLattice(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		grid    string
+		uShape  string
+		dims    []int
+		metric  string
+		maxDist float64
+		neighbs int
+	}{
+		{"planar", "rectangle", []int{4, 3}, "lattice", 5, 4},
+		{"planar", "rectangle", []int{4, 3}, "lattice-chebyshev", 3, 8},
+		{"toroid", "rectangle", []int{4, 4}, "lattice", 4, 4},
+		{"toroid", "rectangle", []int{4, 4}, "lattice-chebyshev", 2, 8},
+		{"cylinder", "rectangle", []int{4, 3}, "lattice", 4, 4},
+		{"planar", "hexagon", []int{4, 4}, "lattice", 5, 6},
+		{"planar", "hexagon", []int{4, 4}, "lattice-chebyshev", 5, 6},
+		{"toroid", "hexagon", []int{4, 6}, "lattice", 4, 6},
+		{"planar", "rectangle", []int{5}, "lattice", 4, 2},
+		{"planar", "rectangle", []int{2, 3, 2}, "lattice-chebyshev", 2, 11},
+	}
+
+	for _, tc := range testCases {
+		c := &Config{Dims: tc.dims, Grid: tc.grid, UShape: tc.uShape, GridMetric: tc.metric}
+		grid, err := NewGrid(c)
+		assert.NoError(err)
+		distMx := grid.distMx()
+		assert.Equal(tc.maxDist, mat64.Max(distMx), "%s %s %s", tc.grid, tc.uShape, tc.metric)
+		assert.Equal(tc.maxDist, grid.maxDist(), "%s %s %s", tc.grid, tc.uShape, tc.metric)
+		maxNeighbs := 0
+		for i := 0; i < grid.Units(); i++ {
+			row := grid.row(i)
+			neighbs := 0
+			for j := 0; j < grid.Units(); j++ {
+				assert.Equal(distMx.At(i, j), row[j])
+				if row[j] == 1 {
+					neighbs++
+				}
+				// chebyshev steps can only be shorter than hops on rectangle grids
+				hops, err := grid.Hops(i, j)
+				assert.NoError(err)
+				if tc.uShape == "hexagon" || tc.metric == "lattice" {
+					assert.Equal(float64(hops), row[j])
+				} else {
+					assert.True(row[j] <= float64(hops))
+				}
+			}
+			if neighbs > maxNeighbs {
+				maxNeighbs = neighbs
+			}
+		}
+		assert.Equal(tc.neighbs, maxNeighbs, "%s %s %s", tc.grid, tc.uShape, tc.metric)
+	}
+}
+
+func TestGridConnectivity(t *testing.T) {
+	assert := assert.New(t)
+
+	grid, err := NewGrid(&Config{Dims: []int{2, 3}, Grid: "planar", UShape: "rectangle"})
+	assert.NoError(err)
+	conn := grid.Connectivity()
+	assert.Len(conn, 6)
+	for unit, neighbs := range conn {
+		expected, err := grid.Neighbors(unit)
+		assert.NoError(err)
+		assert.Equal(expected, neighbs)
+	}
+	assert.Equal([]int{1, 2}, conn[0])
+}
@@ -0,0 +1,317 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// TrainSeq trains the SOM using the sequential (online) Kohonen algorithm.
+// In each of the iters iterations TrainSeq picks the next sample from data matrix,
+// cycling through its rows in order, finds its Best Match Unit (BMU) and moves the
+// codebook vectors towards the sample proportionally to the learning rate and
+// the neighbourhood function evaluated on the grid distance from the BMU.
+// Missing values (NaN) of data samples are ignored in both BMU search and codebook updates.
+// Learning rate and radius are decayed using the strategies set in map configuration.
+// Once the training finishes, BMUs of all data samples are stored in the map.
+// TrainSeq returns error if the data matrix is nil, its dimensions don't match
+// the codebook dimensions or if the number of iterations is not positive.
+func (m *Map) TrainSeq(data *mat64.Dense, iters int) error {
+	if err := validateTrainData(m, data, iters); err != nil {
+		return err
+	}
+	sched := m.schedule()
+	neighbFn := Neighb[m.config.NeighbFn]
+	rows, cols := data.Dims()
+	mUnits, _ := m.codebook.Dims()
+	// codebook vector norms are updated along with the codebook vectors
+	cbNorms := sqNorms(m.codebook)
+	for i := 0; i < iters; i++ {
+		// decay learning rate and radius
+		lr := sched.lr(i, iters)
+		r := sched.r(i, iters)
+		// pick data sample and find its BMU
+		sample := data.View(i%rows, 0, 1, cols).(*mat64.Dense)
+		bmus, _, err := m.closestUnits(sample, cbNorms)
+		if err != nil {
+			return err
+		}
+		x := sample.RawRowView(0)
+		gridDist := m.grid.row(bmus[0])
+		// update codebook vectors
+		for j := 0; j < mUnits; j++ {
+			h := neighbFn(gridDist[j], r)
+			if h == 0 {
+				continue
+			}
+			cbRow := m.codebook.RawRowView(j)
+			for k := range cbRow {
+				if math.IsNaN(x[k]) {
+					continue
+				}
+				cbRow[k] += lr * h * (x[k] - cbRow[k])
+			}
+			cbNorms[j] = floats.Dot(cbRow, cbRow)
+		}
+	}
+	return m.updateBMUs(data)
+}
+
+// TrainBatch trains the SOM using the batch map algorithm.
+// In each of the iters epochs TrainBatch finds BMUs of all data samples and then
+// recomputes every codebook vector as a mean of data samples weighted by the
+// neighbourhood function evaluated on the grid distance between the codebook
+// vector unit and the samples' BMUs. Radius is decayed in every epoch using
+// the strategy set in map configuration; learning rate is not used.
+// Missing values (NaN) of data samples are ignored in both BMU search and the means,
+// so incomplete data samples contribute only their observed features.
+// Codebook vector elements whose neighbourhood contains no observed data values
+// are left unchanged.
+// Once the training finishes, BMUs of all data samples are stored in the map.
+// TrainBatch returns error if the data matrix is nil, its dimensions don't match
+// the codebook dimensions or if the number of epochs is not positive.
+func (m *Map) TrainBatch(data *mat64.Dense, iters int) error {
+	if err := validateTrainData(m, data, iters); err != nil {
+		return err
+	}
+	sched := m.schedule()
+	neighbFn := Neighb[m.config.NeighbFn]
+	_, cols := data.Dims()
+	mUnits, _ := m.codebook.Dims()
+	// neighbourhood weighted sums of data values and their weights of each map unit
+	num := mat64.NewDense(mUnits, cols, nil)
+	den := mat64.NewDense(mUnits, cols, nil)
+	for i := 0; i < iters; i++ {
+		r := sched.r(i, iters)
+		// assign data samples to BMUs and sum them up per BMU
+		sums, counts, err := m.batchSums(data)
+		if err != nil {
+			return err
+		}
+		for _, mx := range []*mat64.Dense{num, den} {
+			mxData := mx.RawMatrix().Data
+			for j := range mxData {
+				mxData[j] = 0.0
+			}
+		}
+		// spread the BMU sums over their neighbourhoods
+		for k := 0; k < mUnits; k++ {
+			count := counts.RawRowView(k)
+			if floats.Sum(count) == 0 {
+				continue
+			}
+			gridDist := m.grid.row(k)
+			sum := sums.RawRowView(k)
+			for j := 0; j < mUnits; j++ {
+				h := neighbFn(gridDist[j], r)
+				if h == 0 {
+					continue
+				}
+				floats.AddScaled(num.RawRowView(j), h, sum)
+				floats.AddScaled(den.RawRowView(j), h, count)
+			}
+		}
+		// recompute codebook vectors
+		for j := 0; j < mUnits; j++ {
+			cbRow := m.codebook.RawRowView(j)
+			numRow, denRow := num.RawRowView(j), den.RawRowView(j)
+			for k := range cbRow {
+				if denRow[k] == 0 {
+					continue
+				}
+				cbRow[k] = numRow[k] / denRow[k]
+			}
+		}
+	}
+	return m.updateBMUs(data)
+}
+
+// batchSums finds BMUs of all samples in data matrix and stores them in the map.
+// It returns a matrix whose rows contain sums of data samples which share the same BMU
+// and a matrix whose rows contain the number of observed values of each feature of the
+// data samples mapped to each map unit. Missing values (NaN) are not summed up.
+// Data matrix rows are split into as many contiguous partitions as there are workers
+// set in map configuration and each partition is processed in a separate goroutine.
+// Partial results are merged in partition order, so the results are the same as if the
+// partitions were processed sequentially.
+// It returns error if any of the BMUs could not be found.
+func (m *Map) batchSums(data *mat64.Dense) (*mat64.Dense, *mat64.Dense, error) {
+	rows, _ := data.Dims()
+	if len(m.bmus) != rows {
+		m.bmus = make([]int, rows)
+	}
+	cbNorms := sqNorms(m.codebook)
+	parts := partition(rows, m.config.Workers)
+	sums := make([]*mat64.Dense, len(parts))
+	counts := make([]*mat64.Dense, len(parts))
+	errs := make([]error, len(parts))
+	var wg sync.WaitGroup
+	for i := range parts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sums[i], counts[i], errs[i] = m.partialSums(data, cbNorms, parts[i][0], parts[i][1])
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return reduceSums(sums, counts)
+}
+
+// partialSums finds BMUs of data samples stored in data matrix rows in range [from, to)
+// and stores them in the map. cbNorms must contain squared norms of the codebook vectors.
+// It returns a matrix whose rows contain sums of the data samples which share the same BMU
+// and a matrix whose rows contain the number of observed values of each feature of the
+// data samples mapped to each map unit. Missing values (NaN) are not summed up.
+// It returns error if any of the BMUs could not be found.
+func (m *Map) partialSums(data *mat64.Dense, cbNorms []float64, from, to int) (*mat64.Dense, *mat64.Dense, error) {
+	_, cols := data.Dims()
+	mUnits, _ := m.codebook.Dims()
+	sums := mat64.NewDense(mUnits, cols, nil)
+	counts := mat64.NewDense(mUnits, cols, nil)
+	part := data.View(from, 0, to-from, cols).(*mat64.Dense)
+	bmus, _, err := m.closestUnits(part, cbNorms)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, bmu := range bmus {
+		m.bmus[from+i] = bmu
+		sum, count := sums.RawRowView(bmu), counts.RawRowView(bmu)
+		for k, x := range part.RawRowView(i) {
+			if math.IsNaN(x) {
+				continue
+			}
+			sum[k] += x
+			count[k]++
+		}
+	}
+	return sums, counts, nil
+}
+
+// reduceSums adds up partial sums and observed value counts in the order they are stored
+// in slices. It returns error if no partial results are supplied.
+func reduceSums(sums, counts []*mat64.Dense) (*mat64.Dense, *mat64.Dense, error) {
+	if len(sums) == 0 || len(sums) != len(counts) {
+		return nil, nil, fmt.Errorf("Invalid partial results supplied: %d\n", len(sums))
+	}
+	total := mat64.DenseCopyOf(sums[0])
+	totalCounts := mat64.DenseCopyOf(counts[0])
+	for i := 1; i < len(sums); i++ {
+		total.Add(total, sums[i])
+		totalCounts.Add(totalCounts, counts[i])
+	}
+	return total, totalCounts, nil
+}
+
+// partition splits n items into at most parts contiguous partitions of nearly equal size.
+// It returns a slice of [from, to) index pairs. If parts is not positive, a single
+// partition which contains all the items is returned.
+func partition(n, parts int) [][2]int {
+	if parts < 1 {
+		parts = 1
+	}
+	if parts > n {
+		parts = n
+	}
+	ranges := make([][2]int, parts)
+	from := 0
+	for i := 0; i < parts; i++ {
+		size := n / parts
+		if i < n%parts {
+			size++
+		}
+		ranges[i] = [2]int{from, from + size}
+		from += size
+	}
+	return ranges
+}
+
+// schedule holds learning rate and radius decay schedules used in training
+type schedule struct {
+	// lRate and lRateFinal are initial and final learning rates
+	lRate, lRateFinal float64
+	// radius and radiusFinal are initial and final neighbourhood radii
+	radius, radiusFinal float64
+	// lDecay and rDecay are learning rate and radius decay functions
+	lDecay, rDecay DecayFunc
+}
+
+// lr returns learning rate at step out of steps training steps
+func (s schedule) lr(step, steps int) float64 {
+	return s.lDecay(s.lRate, s.lRateFinal, step, steps)
+}
+
+// r returns neighbourhood radius at step out of steps training steps
+func (s schedule) r(step, steps int) float64 {
+	return s.rDecay(s.radius, s.radiusFinal, step, steps)
+}
+
+// schedule returns learning rate and radius decay schedules used in training.
+// If learning rate is not set in map configuration, it defaults to 0.5.
+// If final learning rate is not set, it defaults to 1% of the initial learning rate.
+// If radius is not set in map configuration, it defaults to half of
+// the largest distance between any two map units.
+// If final radius is not set, it defaults to 1 or initial radius if it's smaller than 1.
+func (m *Map) schedule() schedule {
+	s := schedule{
+		lRate:       m.config.LRate,
+		lRateFinal:  m.config.LRateFinal,
+		radius:      m.config.Radius,
+		radiusFinal: m.config.RadiusFinal,
+		lDecay:      Decay[m.config.LDecay],
+		rDecay:      Decay[m.config.RDecay],
+	}
+	if s.lRate == 0 {
+		s.lRate = 0.5
+	}
+	if s.lRateFinal == 0 {
+		s.lRateFinal = s.lRate / 100
+	}
+	if s.radius == 0 {
+		s.radius = m.grid.maxDist() / 2
+	}
+	if s.radiusFinal == 0 {
+		s.radiusFinal = 1.0
+	}
+	s.lRateFinal = math.Min(s.lRateFinal, s.lRate)
+	s.radiusFinal = math.Min(s.radiusFinal, s.radius)
+	return s
+}
+
+// updateBMUs finds BMUs of all samples stored in data matrix and stores them in the map
+// It returns error if any of the BMUs could not be found.
+func (m *Map) updateBMUs(data *mat64.Dense) error {
+	bmus, _, err := m.BMUsFor(data)
+	if err != nil {
+		return err
+	}
+	m.bmus = bmus
+	return nil
+}
+
+// validateTrainData checks whether the map can be trained on the supplied data
+// It returns error if data is nil, its dimensions don't match the codebook dimensions
+// or if the requested number of training iterations is not positive
+func validateTrainData(m *Map, data *mat64.Dense, iters int) error {
+	if data == nil {
+		return fmt.Errorf("Invalid data matrix: %v\n", data)
+	}
+	rows, cols := data.Dims()
+	if rows == 0 {
+		return fmt.Errorf("Insufficient number of samples: %d\n", rows)
+	}
+	if _, cbCols := m.codebook.Dims(); cols != cbCols {
+		return fmt.Errorf("Data dimension mismatch. Expected: %d, got: %d\n", cbCols, cols)
+	}
+	if iters <= 0 {
+		return fmt.Errorf("Invalid number of training iterations: %d\n", iters)
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+package som
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/milosgajdos83/gosom/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// meanQuantError computes mean distance between data samples and their BMUs
+func meanQuantError(m *Map, data *mat64.Dense) float64 {
+	rows, _ := data.Dims()
+	qe := 0.0
+	for i := 0; i < rows; i++ {
+		_, dist, _ := m.BMU(data.RowView(i))
+		qe += dist
+	}
+	return qe / float64(rows)
+}
+
+func TestTrainSeq(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, neighbFn := range []string{"gaussian", "bubble"} {
+		c := *cSom
+		c.NeighbFn = neighbFn
+		c.Radius = 1
+		c.LRate = 1
+		m, err := NewMap(&c, dataMx)
+		assert.NotNil(m)
+		assert.NoError(err)
+		qeInit := meanQuantError(m, dataMx)
+		err = m.TrainSeq(dataMx, 100)
+		assert.NoError(err)
+		assert.True(meanQuantError(m, dataMx) < qeInit)
+		rows, _ := dataMx.Dims()
+		assert.Len(m.BMUs(), rows)
+		for i, bmu := range m.BMUs() {
+			expBmu, _, _ := m.BMU(dataMx.RowView(i))
+			assert.Equal(expBmu, bmu)
+		}
+	}
+	// default radius and learning rate
+	m, err := NewMap(cSom, dataMx)
+	assert.NoError(err)
+	err = m.TrainSeq(dataMx, 10)
+	assert.NoError(err)
+	// nil data
+	err = m.TrainSeq(nil, 10)
+	assert.Error(err)
+	// data dimension mismatch
+	err = m.TrainSeq(mat64.NewDense(2, 2, []float64{1, 2, 3, 4}), 10)
+	assert.Error(err)
+	// invalid number of iterations
+	err = m.TrainSeq(dataMx, 0)
+	assert.Error(err)
+}
+
+func TestTrainBatch(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, neighbFn := range []string{"gaussian", "bubble"} {
+		c := *cSom
+		c.NeighbFn = neighbFn
+		c.Radius = 1
+		m, err := NewMap(&c, dataMx)
+		assert.NotNil(m)
+		assert.NoError(err)
+		qeInit := meanQuantError(m, dataMx)
+		err = m.TrainBatch(dataMx, 10)
+		assert.NoError(err)
+		assert.True(meanQuantError(m, dataMx) < qeInit)
+		rows, _ := dataMx.Dims()
+		assert.Len(m.BMUs(), rows)
+		for i, bmu := range m.BMUs() {
+			expBmu, _, _ := m.BMU(dataMx.RowView(i))
+			assert.Equal(expBmu, bmu)
+		}
+		// batch training is deterministic given the initial codebook
+		m2, err := NewMap(&c, dataMx)
+		assert.NoError(err)
+		err = m2.TrainBatch(dataMx, 10)
+		assert.NoError(err)
+		assert.True(mat64.Equal(m.Codebook(), m2.Codebook()))
+	}
+	// default radius
+	m, err := NewMap(cSom, dataMx)
+	assert.NoError(err)
+	err = m.TrainBatch(dataMx, 5)
+	assert.NoError(err)
+	// nil data
+	err = m.TrainBatch(nil, 10)
+	assert.Error(err)
+	// data dimension mismatch
+	err = m.TrainBatch(mat64.NewDense(2, 2, []float64{1, 2, 3, 4}), 10)
+	assert.Error(err)
+	// invalid number of epochs
+	err = m.TrainBatch(dataMx, -1)
+	assert.Error(err)
+}
+
+func TestTrainBatchWorkers(t *testing.T) {
+	assert := assert.New(t)
+
+	rows, _ := dataMx.Dims()
+	for _, workers := range []int{0, 1, 2, 3, 10} {
+		c := *cSom
+		c.Radius = 1
+		c.Workers = workers
+		m, err := NewMap(&c, dataMx)
+		assert.NoError(err)
+		// partial sums computed sequentially
+		parts := partition(rows, workers)
+		sums := make([]*mat64.Dense, len(parts))
+		counts := make([]*mat64.Dense, len(parts))
+		for i, p := range parts {
+			sums[i], counts[i], err = m.partialSums(dataMx, sqNorms(m.Codebook()), p[0], p[1])
+			assert.NoError(err)
+		}
+		seqSums, seqCounts, err := reduceSums(sums, counts)
+		assert.NoError(err)
+		seqBmus := make([]int, rows)
+		copy(seqBmus, m.BMUs())
+		// partial sums computed concurrently
+		parSums, parCounts, err := m.batchSums(dataMx)
+		assert.NoError(err)
+		assert.True(mat64.Equal(seqSums, parSums))
+		assert.True(mat64.Equal(seqCounts, parCounts))
+		assert.Equal(seqBmus, m.BMUs())
+		// concurrent training is deterministic
+		err = m.TrainBatch(dataMx, 10)
+		assert.NoError(err)
+		m2, err := NewMap(&c, dataMx)
+		assert.NoError(err)
+		err = m2.TrainBatch(dataMx, 10)
+		assert.NoError(err)
+		assert.True(mat64.Equal(m.Codebook(), m2.Codebook()))
+	}
+	// no partial results
+	sums, counts, err := reduceSums(nil, nil)
+	assert.Nil(sums)
+	assert.Nil(counts)
+	assert.Error(err)
+}
+
+func TestPartition(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		n        int
+		parts    int
+		expected [][2]int
+	}{
+		{5, 0, [][2]int{{0, 5}}},
+		{5, 1, [][2]int{{0, 5}}},
+		{5, 2, [][2]int{{0, 3}, {3, 5}}},
+		{5, 3, [][2]int{{0, 2}, {2, 4}, {4, 5}}},
+		{2, 4, [][2]int{{0, 1}, {1, 2}}},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(tc.expected, partition(tc.n, tc.parts))
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	assert := assert.New(t)
+
+	c := *cSom
+	c.Radius = 0
+	c.LRate = 0
+	m, err := NewMap(&c, dataMx)
+	assert.NoError(err)
+	// default schedule parameters
+	s := m.schedule()
+	assert.Equal(0.5, s.lRate)
+	assert.Equal(0.005, s.lRateFinal)
+	assert.Equal(mat64.Max(m.GridDist())/2, s.radius)
+	assert.Equal(1.0, s.radiusFinal)
+	assert.Equal(s.lRate, s.lr(0, 10))
+	assert.Equal(s.radius, s.r(0, 10))
+	// explicit schedule parameters
+	c.Radius, c.RadiusFinal = 0.8, 0.2
+	c.LRate, c.LRateFinal = 0.3, 0.1
+	c.RDecay, c.LDecay = "exp", "inv"
+	m, err = NewMap(&c, dataMx)
+	assert.NoError(err)
+	s = m.schedule()
+	assert.InDelta(0.2, s.r(10, 10), 0.0001)
+	assert.InDelta(0.1, s.lr(10, 10), 0.0001)
+	// final radius defaults to initial radius when it's smaller than 1
+	c.RadiusFinal = 0
+	m, err = NewMap(&c, dataMx)
+	assert.NoError(err)
+	assert.Equal(0.8, m.schedule().radiusFinal)
+}
+
+func TestTrain1D3D(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		uShape string
+		dims   []int
+	}{
+		{"hexagon", []int{6}},
+		{"rectangle", []int{6}},
+		{"rectangle", []int{2, 2, 2}},
+	}
+
+	for _, tc := range testCases {
+		for _, grid := range []string{"planar", "toroid"} {
+			c := *cSom
+			c.UShape, c.Dims, c.Grid = tc.uShape, tc.dims, grid
+			c.Radius = 1
+			m, err := NewMap(&c, dataMx)
+			assert.NoError(err)
+			rows, _ := m.GridDist().Dims()
+			assert.Equal(utils.IntProduct(tc.dims), rows)
+			qeInit := meanQuantError(m, dataMx)
+			assert.NoError(m.TrainSeq(dataMx, 50))
+			assert.NoError(m.TrainBatch(dataMx, 5))
+			assert.True(meanQuantError(m, dataMx) < qeInit)
+			bmu, _, err := m.BMU(dataMx.RowView(0))
+			assert.NoError(err)
+			assert.Equal(m.BMUs()[0], bmu)
+		}
+	}
+}
+
+func TestTrainGridCache(t *testing.T) {
+	assert := assert.New(t)
+
+	c := *cSom
+	c.Radius = 1
+	m, err := NewMap(&c, dataMx)
+	assert.NoError(err)
+	c.GridCache = 3
+	mCache, err := NewMap(&c, dataMx)
+	assert.NoError(err)
+	// caching grid distances does not change training results
+	assert.NoError(m.TrainSeq(dataMx, 20))
+	assert.NoError(mCache.TrainSeq(dataMx, 20))
+	assert.True(mat64.Equal(m.Codebook(), mCache.Codebook()))
+	assert.NoError(m.TrainBatch(dataMx, 5))
+	assert.NoError(mCache.TrainBatch(dataMx, 5))
+	assert.True(mat64.Equal(m.Codebook(), mCache.Codebook()))
+	assert.True(len(mCache.grid.cache) <= 3)
+}
+
+func TestTrainLattice(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, metric := range []string{"lattice", "lattice-chebyshev"} {
+		c := *cSom
+		c.Dims, c.GridMetric, c.NeighbFn = []int{4, 4}, metric, "bubble"
+		c.Radius = 1
+		m, err := NewMap(&c, dataMx)
+		assert.NoError(err)
+		// lattice grid distances are whole numbers of steps
+		gridDist := m.GridDist()
+		rows, cols := gridDist.Dims()
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				dist := gridDist.At(i, j)
+				assert.Equal(math.Floor(dist), dist)
+			}
+		}
+		qeInit := meanQuantError(m, dataMx)
+		assert.NoError(m.TrainSeq(dataMx, 50))
+		assert.NoError(m.TrainBatch(dataMx, 5))
+		assert.True(meanQuantError(m, dataMx) < qeInit)
+	}
+}
+
+func TestTrainMissing(t *testing.T) {
+	assert := assert.New(t)
+
+	data := mat64.DenseCopyOf(dataMx)
+	for _, pos := range [][2]int{{0, 1}, {2, 3}, {4, 0}} {
+		data.Set(pos[0], pos[1], math.NaN())
+	}
+	rows, cols := data.Dims()
+	for _, train := range []string{"seq", "batch"} {
+		c := *cSom
+		c.Radius = 1
+		c.LRate = 1
+		m, err := NewMap(&c, data)
+		assert.NoError(err)
+		qeInit := meanQuantError(m, data)
+		if train == "seq" {
+			err = m.TrainSeq(data, 100)
+		} else {
+			err = m.TrainBatch(data, 10)
+		}
+		assert.NoError(err)
+		// incomplete samples do not spoil the codebook
+		for _, v := range m.Codebook().RawMatrix().Data {
+			assert.False(math.IsNaN(v), train)
+		}
+		assert.True(meanQuantError(m, data) < qeInit, train)
+		assert.Len(m.BMUs(), rows)
+	}
+
+	// missing values are neither summed up nor counted
+	m, err := NewMap(cSom, data)
+	assert.NoError(err)
+	sums, counts, err := m.batchSums(data)
+	assert.NoError(err)
+	for _, v := range sums.RawMatrix().Data {
+		assert.False(math.IsNaN(v))
+	}
+	mUnits, _ := counts.Dims()
+	for j := 0; j < cols; j++ {
+		count := 0.0
+		for k := 0; k < mUnits; k++ {
+			count += counts.At(k, j)
+		}
+		expCount := float64(rows)
+		if j != 2 {
+			expCount--
+		}
+		assert.Equal(expCount, count)
+	}
+}
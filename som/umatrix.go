@@ -0,0 +1,132 @@
+package som
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/gonum/floats"
+	"github.com/gonum/matrix/mat64"
+)
+
+// UMatrixStat maps supported statistics of codebook vector distances used in U-matrix
+// to their implementations. You can register your own statistic by adding it to this map.
+var UMatrixStat = map[string]func([]float64) float64{
+	"mean":   mean,
+	"median": median,
+	"max":    floats.Max,
+	"min":    floats.Min,
+}
+
+// UMatrix returns a slice which contains U-matrix values of all SOM units. U-matrix value
+// of a SOM unit is the statistic of the distances between its codebook vector and the codebook
+// vectors of its immediate neighbours on the map grid: see Grid.Neighbors. Supported statistics
+// are listed in UMatrixStat. Distances are measured using the map distance metric.
+// It returns error if the requested statistic is not supported.
+func (m Map) UMatrix(stat string) ([]float64, error) {
+	statFn, ok := UMatrixStat[stat]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported U-matrix statistic: %s\n", stat)
+	}
+	distFn, _ := metricFunc(m.config.Metric)
+	umatrix := make([]float64, m.grid.Units())
+	for unit := range umatrix {
+		neighbs, err := m.grid.Neighbors(unit)
+		if err != nil {
+			return nil, err
+		}
+		dists := make([]float64, len(neighbs))
+		for i, n := range neighbs {
+			if dists[i], err = distFn(m.codebook.RowView(unit), m.codebook.RowView(n)); err != nil {
+				return nil, err
+			}
+		}
+		umatrix[unit] = statFn(dists)
+	}
+	return umatrix, nil
+}
+
+// UMatrixFull returns full U-matrix of 1D or 2D map. Full U-matrix of a map with R rows
+// and C columns has 2*R-1 rows and 2*C-1 columns: SOM unit in row r and column c is stored
+// in row 2*r and column 2*c, the cells between the units store the distances between their
+// codebook vectors. 1D maps are stored in a single row.
+// Cells between 4 rectangle units contain average of the diagonal distances divided by sqrt(2).
+// Odd rows of hexagon units are shifted to the right, so the cells between 4 hexagon units
+// contain the distance between the two units which are neighbours.
+// Unit cells contain U-matrix values computed with the requested statistic: see UMatrix.
+// Distances between the units which are neighbours only due to wrapping of toroid and
+// cylinder grids are not stored in the full U-matrix, but they are taken into account
+// in the unit cells.
+// It returns error if the requested statistic is not supported or if the map is 3D.
+func (m Map) UMatrixFull(stat string) (*mat64.Dense, error) {
+	dims := m.grid.Dims()
+	if len(dims) > 2 {
+		return nil, fmt.Errorf("Unsupported U-matrix dimensions: %d\n", len(dims))
+	}
+	umatrix, err := m.UMatrix(stat)
+	if err != nil {
+		return nil, err
+	}
+	rows, cols := 1, dims[0]
+	if len(dims) == 2 {
+		rows, cols = dims[0], dims[1]
+	}
+	// index returns index of the unit in row r and column c
+	index := func(r, c int) int {
+		return r + c*rows
+	}
+	hexagon := strings.EqualFold(m.grid.UShape(), "hexagon")
+	distFn, _ := metricFunc(m.config.Metric)
+	dist := func(i, j int) float64 {
+		// distFn can only fail on vectors of different dimensions
+		d, _ := distFn(m.codebook.RowView(i), m.codebook.RowView(j))
+		return d
+	}
+	full := mat64.NewDense(2*rows-1, 2*cols-1, nil)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			unit := index(r, c)
+			full.Set(2*r, 2*c, umatrix[unit])
+			// right neighbour
+			if c < cols-1 {
+				full.Set(2*r, 2*c+1, dist(unit, index(r, c+1)))
+			}
+			// bottom neighbour
+			if r < rows-1 {
+				full.Set(2*r+1, 2*c, dist(unit, index(r+1, c)))
+			}
+			// diagonal neighbours
+			if r < rows-1 && c < cols-1 {
+				switch {
+				case !hexagon:
+					d1 := dist(unit, index(r+1, c+1))
+					d2 := dist(index(r, c+1), index(r+1, c))
+					full.Set(2*r+1, 2*c+1, (d1+d2)/(2*math.Sqrt2))
+				case r%2 == 0:
+					full.Set(2*r+1, 2*c+1, dist(index(r, c+1), index(r+1, c)))
+				default:
+					full.Set(2*r+1, 2*c+1, dist(unit, index(r+1, c+1)))
+				}
+			}
+		}
+	}
+	return full, nil
+}
+
+// mean returns the arithmetic mean of x
+func mean(x []float64) float64 {
+	return floats.Sum(x) / float64(len(x))
+}
+
+// median returns the median of x
+func median(x []float64) float64 {
+	sorted := make([]float64, len(x))
+	copy(sorted, x)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,141 @@
+package som
+
+import (
+	"testing"
+
+	"github.com/gonum/matrix/mat64"
+	"github.com/stretchr/testify/assert"
+)
+
+// newUMatrixMap returns a map whose codebook is initialized to the provided matrix
+func newUMatrixMap(grid, uShape string, dims []int, codebook *mat64.Dense) (*Map, error) {
+	c := &Config{
+		Dims:   dims,
+		Grid:   grid,
+		UShape: uShape,
+		InitFunc: func(data *mat64.Dense, dims []int) (*mat64.Dense, error) {
+			return codebook, nil
+		},
+		RDecay:   "lin",
+		NeighbFn: "gaussian",
+		LDecay:   "lin",
+	}
+	_, cols := codebook.Dims()
+	return NewMap(c, mat64.NewDense(1, cols, nil))
+}
+
+func TestUMatrix(t *testing.T) {
+	assert := assert.New(t)
+
+	codebook := mat64.NewDense(4, 1, []float64{0, 1, 3, 6})
+	testCases := []struct {
+		grid     string
+		stat     string
+		expected []float64
+	}{
+		{"planar", "mean", []float64{1, 1.5, 2.5, 3}},
+		{"planar", "median", []float64{1, 1.5, 2.5, 3}},
+		{"planar", "max", []float64{1, 2, 3, 3}},
+		{"planar", "min", []float64{1, 1, 2, 3}},
+		{"toroid", "mean", []float64{3.5, 1.5, 2.5, 4.5}},
+	}
+
+	for _, tc := range testCases {
+		m, err := newUMatrixMap(tc.grid, "rectangle", []int{4}, codebook)
+		assert.NoError(err)
+		umatrix, err := m.UMatrix(tc.stat)
+		assert.NoError(err)
+		assert.Equal(tc.expected, umatrix, "%s %s", tc.grid, tc.stat)
+	}
+
+	m, err := newUMatrixMap("planar", "rectangle", []int{4}, codebook)
+	assert.NoError(err)
+	umatrix, err := m.UMatrix("foobar")
+	assert.Nil(umatrix)
+	assert.EqualError(err, "Unsupported U-matrix statistic: foobar\n")
+}
+
+func TestUMatrixFull(t *testing.T) {
+	assert := assert.New(t)
+
+	// 1D map
+	m, err := newUMatrixMap("planar", "rectangle", []int{4}, mat64.NewDense(4, 1, []float64{0, 1, 3, 6}))
+	assert.NoError(err)
+	full, err := m.UMatrixFull("mean")
+	assert.NoError(err)
+	assert.True(mat64.Equal(mat64.NewDense(1, 7, []float64{1, 1, 1.5, 2, 2.5, 3, 3}), full))
+
+	// codebook vectors equal to grid coordinates are one unit apart from their neighbours
+	for _, uShape := range []string{"rectangle", "hexagon"} {
+		for _, grid := range []string{"planar", "toroid"} {
+			dims := []int{4, 3}
+			coords, err := GridCoords(uShape, dims)
+			assert.NoError(err)
+			m, err := newUMatrixMap(grid, uShape, dims, coords)
+			assert.NoError(err)
+			full, err := m.UMatrixFull("max")
+			assert.NoError(err)
+			rows, cols := full.Dims()
+			assert.Equal(7, rows)
+			assert.Equal(5, cols)
+			for i := 0; i < rows; i++ {
+				for j := 0; j < cols; j++ {
+					// wrapped neighbours are further apart
+					if grid == "toroid" && i%2 == 0 && j%2 == 0 {
+						assert.True(full.At(i, j) >= 1.0)
+						continue
+					}
+					assert.InDelta(1.0, full.At(i, j), 1e-9, "%s %s %d %d", grid, uShape, i, j)
+				}
+			}
+		}
+	}
+
+	// hexagon cells between 4 units contain the distance between neighbours
+	codebook := mat64.NewDense(4, 1, []float64{0, 1, 3, 10})
+	m, err = newUMatrixMap("planar", "hexagon", []int{2, 2}, codebook)
+	assert.NoError(err)
+	full, err = m.UMatrixFull("min")
+	assert.NoError(err)
+	// unit 0 (0,0) and unit 1 (1,0) are neighbours, unit 2 (0,1) and unit 3 (1,1) are neighbours,
+	// unit 1 (1,0) and unit 2 (0,1) are neighbours, unit 0 (0,0) and unit 3 (1,1) are not
+	assert.True(mat64.Equal(mat64.NewDense(3, 3, []float64{
+		1, 3, 2,
+		1, 2, 7,
+		1, 9, 7,
+	}), full))
+
+	// 3D map
+	m, err = newUMatrixMap("planar", "rectangle", []int{2, 2, 2}, mat64.NewDense(8, 1, nil))
+	assert.NoError(err)
+	full, err = m.UMatrixFull("mean")
+	assert.Nil(full)
+	assert.Error(err)
+	// unsupported statistic
+	m, err = newUMatrixMap("planar", "rectangle", []int{4}, mat64.NewDense(4, 1, nil))
+	assert.NoError(err)
+	full, err = m.UMatrixFull("foobar")
+	assert.Nil(full)
+	assert.Error(err)
+}
+
+func TestMedian(t *testing.T) {
+	assert := assert.New(t)
+
+	testCases := []struct {
+		x      []float64
+		median float64
+	}{
+		{[]float64{3}, 3},
+		{[]float64{3, 1, 2}, 2},
+		{[]float64{4, 1, 3, 2}, 2.5},
+	}
+
+	for _, tc := range testCases {
+		x := make([]float64, len(tc.x))
+		copy(x, tc.x)
+		assert.Equal(tc.median, median(x))
+		// median does not modify its input
+		assert.Equal(tc.x, x)
+	}
+}